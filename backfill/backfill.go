@@ -0,0 +1,157 @@
+// 历史数据回填：在过去的 tipset 上重新执行链上数据相关的 collector，
+// 输出带时间戳的 OpenMetrics 文本，可以用 promtool tsdb create-blocks-from openmetrics 导入
+
+package backfill
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/config"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/gh-efforts/lotus-monitor/notify"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var log = logging.Logger("monitor/backfill")
+
+// views 只输出由链上状态计算的指标，错误、缓存、耗时等运行时指标没有历史意义
+var views = []*view.View{
+	metrics.ChainHeightView,
+	metrics.ChainBlocksPerTipSetView,
+	metrics.ChainAvgBlocksPerEpochView,
+	metrics.ChainNullRoundRateView,
+	metrics.ChainBaseFeeView,
+	metrics.ChainNetworkRawPowerView,
+	metrics.ChainNetworkQAPowerView,
+	metrics.ChainCirculatingSupplyView,
+	metrics.ChainNetworkVersionView,
+	metrics.BalanceView,
+	metrics.DeadlineCostView,
+	metrics.MinerActivesView,
+	metrics.MinerLivesView,
+	metrics.MinerFaultsView,
+	metrics.MinerAvailableBalanceView,
+	metrics.MinerRawBytePowerView,
+	metrics.MinerQualityAdjPowerView,
+	metrics.BeneficiaryHasTermView,
+	metrics.BeneficiaryQuotaView,
+	metrics.BeneficiaryUsedQuotaView,
+	metrics.BeneficiaryRemainingQuotaView,
+	metrics.BeneficiaryExpirationView,
+	metrics.BeneficiaryPendingView,
+	metrics.BeneficiaryPendingQuotaView,
+	metrics.BeneficiaryPendingExpirationView,
+	metrics.BeneficiaryPendingApprovedView,
+}
+
+type Options struct {
+	From   abi.ChainEpoch
+	To     abi.ChainEpoch
+	Stride abi.ChainEpoch
+	//统计每一步之间监控 miner 的出块数，需要遍历区间内所有 tipset
+	Wins bool
+}
+
+type Backfill struct {
+	ctx        context.Context
+	dc         *config.DynamicConfig
+	collectors []notify.Collector
+	om         *OpenMetrics
+}
+
+func NewBackfill(ctx context.Context, dc *config.DynamicConfig, namespace string, collectors ...notify.Collector) *Backfill {
+	return &Backfill{
+		ctx:        ctx,
+		dc:         dc,
+		collectors: collectors,
+		om:         NewOpenMetrics(namespace),
+	}
+}
+
+// Run 每一步重新注册 views 清空上一步的数据，避免把旧值带到新的时间戳
+func (b *Backfill) Run(opts Options, w io.Writer) error {
+	if opts.Stride <= 0 {
+		return fmt.Errorf("invalid stride: %d", opts.Stride)
+	}
+	if opts.From > opts.To {
+		return fmt.Errorf("from %d is greater than to %d", opts.From, opts.To)
+	}
+
+	vs := views
+	if opts.Wins {
+		vs = append(vs, metrics.BackfillBlockWinsView)
+	}
+
+	prev := opts.From - opts.Stride
+	for h := opts.From; h <= opts.To; h += opts.Stride {
+		if err := view.Register(vs...); err != nil {
+			return err
+		}
+
+		ts, err := b.dc.LotusApi.ChainGetTipSetByHeight(b.ctx, h, types.EmptyTSK)
+		if err != nil {
+			return err
+		}
+		log.Infow("backfill", "height", h, "tipset", ts.Height())
+
+		for _, c := range b.collectors {
+			if err := c.Record(b.ctx, ts); err != nil {
+				log.Errorw("collector record failed", "collector", c.Name(), "height", ts.Height(), "err", err)
+			}
+		}
+		if opts.Wins {
+			if err := b.wins(prev, ts); err != nil {
+				log.Errorw("wins failed", "height", ts.Height(), "err", err)
+			}
+		}
+
+		if err := b.om.Collect(vs, ts.MinTimestamp()); err != nil {
+			return err
+		}
+		view.Unregister(vs...)
+		prev = h
+	}
+
+	return b.om.Write(w)
+}
+
+// wins 统计 (from, ts] 之间监控 miner 的 WinCount
+func (b *Backfill) wins(from abi.ChainEpoch, ts *types.TipSet) error {
+	miners := map[address.Address]int64{}
+	for _, m := range b.dc.MinersList() {
+		miners[m] = 0
+	}
+
+	for cur := ts; cur.Height() > from; {
+		for _, bh := range cur.Blocks() {
+			if _, ok := miners[bh.Miner]; ok {
+				miners[bh.Miner] += bh.ElectionProof.WinCount
+			}
+		}
+		if cur.Height() == 0 {
+			break
+		}
+
+		parent, err := b.dc.LotusApi.ChainGetTipSet(b.ctx, cur.Parents())
+		if err != nil {
+			return err
+		}
+		cur = parent
+	}
+
+	for m, wins := range miners {
+		ctx, _ := tag.New(b.ctx,
+			tag.Upsert(metrics.MinerID, m.String()),
+		)
+		stats.Record(ctx, metrics.BackfillBlockWins.M(wins))
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package backfill
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opencensus.io/stats/view"
+)
+
+// family 同一个指标的所有样本，OpenMetrics 要求同一个指标只能出现一次
+type family struct {
+	name    string
+	help    string
+	samples []string
+}
+
+// OpenMetrics 按时间顺序收集每一步的指标数据，最后一次性输出
+type OpenMetrics struct {
+	namespace string
+	order     []string
+	families  map[string]*family
+}
+
+func NewOpenMetrics(namespace string) *OpenMetrics {
+	return &OpenMetrics{
+		namespace: namespace,
+		families:  make(map[string]*family),
+	}
+}
+
+// Collect 读取 views 当前的数据，样本时间戳为 timestamp（秒）。
+// 分布类型的 view 没有历史意义，跳过
+func (o *OpenMetrics) Collect(views []*view.View, timestamp uint64) error {
+	for _, v := range views {
+		if v.Aggregation.Type == view.AggTypeDistribution {
+			continue
+		}
+
+		rows, err := view.RetrieveData(v.Name)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		name := sanitize(o.namespace + "_" + v.Name)
+		f, ok := o.families[name]
+		if !ok {
+			f = &family{name: name, help: v.Description}
+			if f.help == "" {
+				f.help = v.Measure.Description()
+			}
+			o.families[name] = f
+			o.order = append(o.order, name)
+		}
+
+		for _, row := range rows {
+			var value float64
+			switch d := row.Data.(type) {
+			case *view.LastValueData:
+				value = d.Value
+			case *view.SumData:
+				value = d.Value
+			case *view.CountData:
+				value = float64(d.Value)
+			default:
+				continue
+			}
+
+			labels := make([]string, 0, len(row.Tags))
+			for _, t := range row.Tags {
+				labels = append(labels, fmt.Sprintf(`%s="%s"`, sanitize(t.Key.Name()), escape(t.Value)))
+			}
+			sort.Strings(labels)
+
+			f.samples = append(f.samples, fmt.Sprintf("%s{%s} %s %d", name, strings.Join(labels, ","), strconv.FormatFloat(value, 'g', -1, 64), timestamp))
+		}
+	}
+
+	return nil
+}
+
+// Write 所有指标都按 gauge 输出
+func (o *OpenMetrics) Write(w io.Writer) error {
+	for _, name := range o.order {
+		f := o.families[name]
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, escape(f.help), name); err != nil {
+			return err
+		}
+		for _, s := range f.samples {
+			if _, err := fmt.Fprintln(w, s); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintln(w, "# EOF")
+	return err
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
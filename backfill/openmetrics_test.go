@@ -0,0 +1,54 @@
+package backfill
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+func TestOpenMetrics(t *testing.T) {
+	key, _ := tag.NewKey("miner_id")
+	m := stats.Float64("test/power", "test power", stats.UnitDimensionless)
+	v := &view.View{
+		Measure:     m,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{key},
+	}
+	views := []*view.View{v}
+
+	om := NewOpenMetrics("lotusmonitor")
+	for i, ts := range []uint64{1000, 1030} {
+		if err := view.Register(views...); err != nil {
+			t.Fatal(err)
+		}
+		ctx, _ := tag.New(context.Background(), tag.Upsert(key, "f01000"))
+		stats.Record(ctx, m.M(float64(i+1)))
+		if err := om.Collect(views, ts); err != nil {
+			t.Fatal(err)
+		}
+		view.Unregister(views...)
+	}
+
+	var buf bytes.Buffer
+	if err := om.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP lotusmonitor_test_power test power
+# TYPE lotusmonitor_test_power gauge
+lotusmonitor_test_power{miner_id="f01000"} 1 1000
+lotusmonitor_test_power{miner_id="f01000"} 2 1030
+# EOF
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if strings.Count(buf.String(), "# TYPE") != 1 {
+		t.Fatal("family should be written once")
+	}
+}
@@ -3,16 +3,23 @@ package blocks
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/actors/adt"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/gh-efforts/lotus-monitor/config"
 	"github.com/gh-efforts/lotus-monitor/metrics"
 	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	cbor "github.com/ipfs/go-ipld-cbor"
 	logging "github.com/ipfs/go-log/v2"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
@@ -26,29 +33,52 @@ type Block struct {
 	Height    abi.ChainEpoch `json:"height"`
 	Timestamp uint64         `json:"timestamp"`
 	Took      float64        `json:"took"`
+	//miner 提交块的时间（unix 毫秒），上报时没有填写则使用收到上报的时间；链上发现的块没有
+	Submitted int64 `json:"submitted,omitempty"`
 }
 
+var errNotInTipSet = errors.New("block not in tipset")
+
 type Blocks struct {
-	ctx context.Context
-	dc  *config.DynamicConfig
+	ctx     context.Context
+	dc      *config.DynamicConfig
+	ds      datastore.Batching
+	store   adt.Store
+	checker BlockChecker
 
-	lk     sync.Mutex
-	blocks map[string]Block
+	lk      sync.Mutex
+	blocks  map[string]Block
+	last    abi.ChainEpoch
+	genesis uint64
+	tracked map[address.Address]struct{}
+	//最近观察到的重组，只在 watchChain 中访问
+	reorgs []reorg
 }
 
-func NewBlocks(ctx context.Context, dc *config.DynamicConfig) *Blocks {
+// NewBlocks checker 用来判断孤块是否广播出去，为 nil 时不判断
+func NewBlocks(ctx context.Context, dc *config.DynamicConfig, ds datastore.Batching, checker BlockChecker) (*Blocks, error) {
 	b := &Blocks{
-		ctx:    ctx,
-		dc:     dc,
-		blocks: make(map[string]Block),
+		ctx:     ctx,
+		dc:      dc,
+		ds:      namespace.Wrap(ds, datastore.NewKey("/blocks")),
+		store:   adt.WrapStore(ctx, cbor.NewCborStore(blockstore.NewAPIBlockstore(dc.LotusApi))),
+		checker: checker,
+		blocks:  make(map[string]Block),
+
+		tracked: make(map[address.Address]struct{}),
+	}
+	if err := b.load(); err != nil {
+		return nil, err
 	}
 	b.run()
-	return b
+	b.watchChain()
+	return b, nil
 }
 
 func (b *Blocks) run() {
 	go func() {
 		t := time.NewTicker(time.Duration(b.dc.RecordInterval.Blocks))
+		p := time.NewTicker(time.Hour)
 		for {
 			select {
 			case <-t.C:
@@ -58,6 +88,12 @@ func (b *Blocks) run() {
 				} else {
 					log.Debug("orphanCheck success")
 				}
+				b.luckyRecords()
+			case <-p.C:
+				if err := b.prune(); err != nil {
+					log.Errorw("prune failed", "err", err)
+					metrics.RecordError(b.ctx, "blocks/prune")
+				}
 			case <-b.ctx.Done():
 				return
 			}
@@ -65,7 +101,7 @@ func (b *Blocks) run() {
 	}()
 }
 
-func (b *Blocks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+func (b *Blocks) submit(w http.ResponseWriter, r *http.Request) {
 	var block Block
 	err := json.NewDecoder(r.Body).Decode(&block)
 	if err != nil {
@@ -88,23 +124,68 @@ func (b *Blocks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	b.add(block)
+	if block.Submitted == 0 {
+		block.Submitted = time.Now().UnixMilli()
+	}
+
+	if err := b.add(block); err != nil {
+		log.Error(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		metrics.RecordError(r.Context(), "blocks/StatusInternalServerError")
+		return
+	}
 	b.recordBlockTook(block)
 	log.Infow("received block", "cid", block.Cid, "miner", block.Miner)
 }
 
-func (b *Blocks) add(block Block) {
+func (b *Blocks) add(block Block) error {
 	b.lk.Lock()
 	defer b.lk.Unlock()
 
+	resolved, err := b.isResolved(block.Cid)
+	if err != nil {
+		return err
+	}
+	if resolved {
+		log.Debugw("block already resolved", "cid", block.Cid)
+		return nil
+	}
+
+	if err := b.putPending(block); err != nil {
+		return err
+	}
 	b.blocks[block.Cid] = block
+	return nil
 }
 
-func (b *Blocks) delete(blockCid string) {
+func (b *Blocks) resolve(r BlockRecord) error {
 	b.lk.Lock()
 	defer b.lk.Unlock()
 
-	delete(b.blocks, blockCid)
+	if err := b.putResolved(r); err != nil {
+		return err
+	}
+	delete(b.blocks, r.Cid)
+	return nil
+}
+
+// prune 删除超过 BlockHistory 保留时长的历史记录
+func (b *Blocks) prune() error {
+	if b.dc.BlockHistory == 0 {
+		return nil
+	}
+
+	head, err := b.dc.LotusApi.ChainHead(b.ctx)
+	if err != nil {
+		return err
+	}
+
+	return b.pruneHistory(head.Height() - b.historyEpochs())
+}
+
+// historyEpochs blockHistory 对应的高度数，为 0 时不清理
+func (b *Blocks) historyEpochs() abi.ChainEpoch {
+	return abi.ChainEpoch(time.Duration(b.dc.BlockHistory) / (time.Second * time.Duration(builtin.EpochDurationSeconds)))
 }
 
 func (b *Blocks) filter(head abi.ChainEpoch) []Block {
@@ -121,19 +202,48 @@ func (b *Blocks) filter(head abi.ChainEpoch) []Block {
 	return bb
 }
 
-func (b *Blocks) recordBlockOnchain(block Block) {
+func (b *Blocks) recordBlockOnchain(block Block, reward *BlockReward) {
 	ctx, _ := tag.New(b.ctx,
 		tag.Upsert(metrics.MinerID, block.Miner),
 	)
 
 	stats.Record(ctx, metrics.BlockOnchain.M(1))
+
+	if reward != nil {
+		total := types.BigDivFloat(reward.Total, types.FromFil(1))
+		stats.Record(ctx,
+			metrics.BlockReward.M(total),
+			metrics.BlockRewardTotal.M(total),
+			metrics.BlockWinCount.M(reward.WinCount),
+		)
+	}
 }
 
-func (b *Blocks) recordOrphan(block Block) {
+// recordBlockReverted 撤销 recordBlockOnchain 的累计值，块重新检查后再按结果记录；
+// 奖励分布是单个块的样本，不撤销
+func (b *Blocks) recordBlockReverted(r *BlockRecord) {
+	ctx, _ := tag.New(b.ctx,
+		tag.Upsert(metrics.MinerID, r.Miner),
+	)
+
+	stats.Record(ctx, metrics.BlockOnchain.M(-1))
+
+	if r.Reward != nil {
+		stats.Record(ctx,
+			metrics.BlockRewardTotal.M(-types.BigDivFloat(r.Reward.Total, types.FromFil(1))),
+			metrics.BlockWinCount.M(-r.Reward.WinCount),
+		)
+	}
+}
+
+func (b *Blocks) recordOrphan(block Block, cause string) {
 	ctx, _ := tag.New(b.ctx,
 		tag.Upsert(metrics.MinerID, block.Miner),
 	)
-	stats.Record(ctx, metrics.BlockOrphanCount.M(1))
+	countCtx, _ := tag.New(ctx,
+		tag.Upsert(metrics.OrphanCause, cause),
+	)
+	stats.Record(countCtx, metrics.BlockOrphanCount.M(1))
 
 	ctx, _ = tag.New(ctx,
 		tag.Upsert(metrics.BlockCID, block.Cid),
@@ -174,13 +284,36 @@ func (b *Blocks) orphanCheck() error {
 		if err != nil {
 			return err
 		}
+
+		r := BlockRecord{
+			Block:       block,
+			CheckHeight: head.Height(),
+		}
 		if ts.Contains(c) {
-			b.recordBlockOnchain(block)
+			r.Status = StatusOnchain
+			r.Reward, err = b.blockReward(c, ts)
+			if err != nil {
+				//奖励计算失败不影响孤块检查
+				log.Errorw("blockReward failed", "cid", block.Cid, "err", err)
+				metrics.RecordError(b.ctx, "blocks/blockReward")
+			}
+		} else {
+			r.Status = StatusOrphan
+			r.Orphan, err = b.analyseOrphan(block, c, ts)
+			if err != nil {
+				return err
+			}
+		}
+		if err := b.resolve(r); err != nil {
+			return err
+		}
+
+		if r.Status == StatusOnchain {
+			b.recordBlockOnchain(block, r.Reward)
 		} else {
-			b.recordOrphan(block)
-			log.Infow("orphan block", "cid", block.Cid, "miner", block.Miner)
+			b.recordOrphan(block, r.Orphan.Cause)
+			log.Infow("orphan block", "cid", block.Cid, "miner", block.Miner, "cause", r.Orphan.Cause, "delay", r.Orphan.Delay)
 		}
-		b.delete(block.Cid)
 	}
 
 	return nil
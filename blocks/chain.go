@@ -0,0 +1,191 @@
+package blocks
+
+import (
+	"errors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/gh-efforts/lotus-monitor/notify"
+	"github.com/ipfs/go-datastore"
+)
+
+// watchChain 订阅 ChainNotify，从链上发现监控 miner 出的块，
+// 和 /blocks 上报的块一起进入 orphanCheck 流程。
+func (b *Blocks) watchChain() {
+	notify.Watch(b.ctx, b.dc.LotusApi, "blocks", func(changes []*api.HeadChange) {
+		var reverts, applies []*types.TipSet
+		for _, change := range changes {
+			switch change.Type {
+			case notify.HCCurrent:
+				if err := b.catchUp(change.Val); err != nil {
+					log.Errorw("catchUp failed", "height", change.Val.Height(), "err", err)
+					metrics.RecordError(b.ctx, "blocks/catchUp")
+				}
+			case notify.HCApply:
+				b.applyTipSet(change.Val)
+				applies = append(applies, change.Val)
+			case notify.HCRevert:
+				b.revertTipSet(change.Val)
+				reverts = append(reverts, change.Val)
+			}
+		}
+		b.recordReorg(reverts, applies)
+	})
+}
+
+// catchUp 重新订阅或重启后补齐断开期间的 tipset，最多回溯一个 finality，
+// 从低到高处理，完成后 last 为 head 的高度
+func (b *Blocks) catchUp(head *types.TipSet) error {
+	last := b.lastHeight()
+	if last == 0 || head.Height()-last > policy.ChainFinality {
+		last = head.Height() - 1
+	}
+
+	var tss []*types.TipSet
+	for ts := head; ts.Height() > last; {
+		tss = append(tss, ts)
+
+		parent, err := b.dc.LotusApi.ChainGetTipSet(b.ctx, ts.Parents())
+		if err != nil {
+			return err
+		}
+		ts = parent
+	}
+
+	for i := len(tss) - 1; i >= 0; i-- {
+		b.applyTipSet(tss[i])
+	}
+	return nil
+}
+
+func (b *Blocks) applyTipSet(ts *types.TipSet) {
+	miners := map[address.Address]struct{}{}
+	for _, m := range b.dc.MinersList() {
+		miners[m] = struct{}{}
+		if err := b.trackSince(m, ts.Height()); err != nil {
+			log.Errorw("trackSince failed", "miner", m, "err", err)
+		}
+	}
+
+	for _, bh := range ts.Blocks() {
+		if _, ok := miners[bh.Miner]; !ok {
+			continue
+		}
+
+		block := Block{
+			Cid:       bh.Cid().String(),
+			Miner:     bh.Miner.String(),
+			Height:    bh.Height,
+			Timestamp: bh.Timestamp,
+		}
+		added, err := b.addChainBlock(block)
+		if err != nil {
+			log.Errorw("addChainBlock failed", "cid", block.Cid, "err", err)
+			metrics.RecordError(b.ctx, "blocks/addChainBlock")
+			continue
+		}
+		if added {
+			log.Infow("found block on chain", "cid", block.Cid, "miner", block.Miner, "height", block.Height)
+		}
+	}
+
+	b.setLastHeight(ts.Height())
+}
+
+func (b *Blocks) revertTipSet(ts *types.TipSet) {
+	for _, bh := range ts.Blocks() {
+		if b.pending(bh.Cid().String()) {
+			//留在 pending 里，由 orphanCheck 到期后按主链判断
+			log.Warnw("block reverted", "cid", bh.Cid(), "miner", bh.Miner, "height", bh.Height)
+			continue
+		}
+
+		//重组深度超过 OrphanCheckHeight 时，已经确认上链的块也可能被 revert
+		r, err := b.reopen(bh.Cid().String())
+		if err != nil {
+			log.Errorw("reopen failed", "cid", bh.Cid(), "err", err)
+			metrics.RecordError(b.ctx, "blocks/reopen")
+			continue
+		}
+		if r == nil {
+			continue
+		}
+
+		log.Warnw("onchain block reverted, recheck", "cid", r.Cid, "miner", r.Miner, "height", r.Height)
+		b.recordBlockReverted(r)
+	}
+
+	b.setLastHeight(ts.Height() - 1)
+}
+
+// reopen 把已确认上链的块放回 pending，由 orphanCheck 重新判断；不是已上链的块返回 nil
+func (b *Blocks) reopen(blockCid string) (*BlockRecord, error) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	r, err := b.getResolved(blockCid)
+	if errors.Is(err, datastore.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != StatusOnchain {
+		return nil, nil
+	}
+
+	if err := b.putReopened(r.Block); err != nil {
+		return nil, err
+	}
+	b.blocks[r.Cid] = r.Block
+	return &r, nil
+}
+
+// addChainBlock 只添加尚未上报也尚未检查过的块，返回是否为新块
+func (b *Blocks) addChainBlock(block Block) (bool, error) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	if _, ok := b.blocks[block.Cid]; ok {
+		return false, nil
+	}
+	resolved, err := b.isResolved(block.Cid)
+	if err != nil || resolved {
+		return false, err
+	}
+
+	if err := b.putPending(block); err != nil {
+		return false, err
+	}
+	b.blocks[block.Cid] = block
+	return true, nil
+}
+
+func (b *Blocks) pending(blockCid string) bool {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	_, ok := b.blocks[blockCid]
+	return ok
+}
+
+func (b *Blocks) lastHeight() abi.ChainEpoch {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	return b.last
+}
+
+func (b *Blocks) setLastHeight(h abi.ChainEpoch) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	b.last = h
+	if err := b.putLastHeight(h); err != nil {
+		log.Errorw("putLastHeight failed", "height", h, "err", err)
+	}
+}
@@ -0,0 +1,123 @@
+package blocks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+)
+
+// ServeHTTP
+// POST /blocks 上报出块
+// GET /blocks/{cid} 查询块状态
+// GET /blocks?miner=&from=&to= 查询出块历史
+func (b *Blocks) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		b.submit(w, r)
+	case http.MethodGet:
+		if id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/blocks"), "/"); id != "" {
+			b.getHandle(w, r, id)
+		} else {
+			b.listHandle(w, r)
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (b *Blocks) getHandle(w http.ResponseWriter, r *http.Request, id string) {
+	log.Debugw("getHandle", "path", r.URL.Path)
+
+	if _, err := cid.Decode(id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	record, err := b.Get(id)
+	if errors.Is(err, datastore.ErrNotFound) {
+		http.Error(w, "block not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Errorw("get block failed", "cid", id, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		metrics.RecordError(r.Context(), "blocks/StatusInternalServerError")
+		return
+	}
+
+	data, err := json.Marshal(&record)
+	if err != nil {
+		log.Errorw("marshal block record failed", "cid", id, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		metrics.RecordError(r.Context(), "blocks/StatusInternalServerError")
+		return
+	}
+
+	w.Write(data)
+}
+
+func (b *Blocks) listHandle(w http.ResponseWriter, r *http.Request) {
+	log.Debugw("listHandle", "path", r.URL.Path, "query", r.URL.RawQuery)
+
+	q := r.URL.Query()
+	miner := q.Get("miner")
+	if miner != "" {
+		maddr, err := address.NewFromString(miner)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		miner = maddr.String()
+	}
+
+	from, err := parseEpoch(q.Get("from"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := parseEpoch(q.Get("to"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	records, err := b.History(miner, from, to)
+	if err != nil {
+		log.Errorw("block history failed", "miner", miner, "from", from, "to", to, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		metrics.RecordError(r.Context(), "blocks/StatusInternalServerError")
+		return
+	}
+	if records == nil {
+		records = []BlockRecord{}
+	}
+
+	data, err := json.Marshal(&records)
+	if err != nil {
+		log.Errorw("marshal block history failed", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		metrics.RecordError(r.Context(), "blocks/StatusInternalServerError")
+		return
+	}
+
+	w.Write(data)
+}
+
+func parseEpoch(s string) (abi.ChainEpoch, error) {
+	if s == "" {
+		return 0, nil
+	}
+	h, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return abi.ChainEpoch(h), nil
+}
@@ -0,0 +1,160 @@
+package blocks
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/ipfs/go-datastore"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+var sincePrefix = datastore.NewKey("/meta/since")
+
+var luckyWindows = map[string]abi.ChainEpoch{
+	"1d":  builtin.EpochsInDay,
+	"7d":  builtin.EpochsInDay * 7,
+	"30d": builtin.EpochsInDay * 30,
+}
+
+// luckyRecords 根据链上数据计算幸运值，不依赖 filfox：
+// 每天期望出块数 = miner QAP / 全网 QAP * ExpectedLeadersPerEpoch * EpochsInDay
+// 幸运值 = 窗口内实际 WinCount / 窗口内期望出块数
+func (b *Blocks) luckyRecords() {
+	stop := metrics.Timer(b.ctx, "blocks/luckyRecords")
+	defer stop()
+
+	head, err := b.dc.LotusApi.ChainHead(b.ctx)
+	if err != nil {
+		log.Errorw("luckyRecords failed", "err", err)
+		metrics.RecordError(b.ctx, "blocks/luckyRecords")
+		return
+	}
+
+	for _, maddr := range b.dc.MinersList() {
+		if err := b.luckyRecord(maddr, head); err != nil {
+			log.Errorw("luckyRecord failed", "miner", maddr, "err", err)
+			metrics.RecordError(b.ctx, "blocks/luckyRecord")
+		} else {
+			log.Debugw("luckyRecord success", "miner", maddr)
+		}
+	}
+}
+
+func (b *Blocks) luckyRecord(maddr address.Address, head *types.TipSet) error {
+	ctx, _ := tag.New(b.ctx,
+		tag.Upsert(metrics.MinerID, maddr.String()),
+	)
+
+	mp, err := b.dc.LotusApi.StateMinerPower(ctx, maddr, head.Key())
+	if err != nil {
+		return err
+	}
+	if !mp.HasMinPower || mp.TotalPower.QualityAdjPower.IsZero() {
+		log.Debugw("miner has no min power, skip lucky value", "miner", maddr)
+		return nil
+	}
+
+	perDay := types.BigDivFloat(
+		big.Mul(mp.MinerPower.QualityAdjPower, big.NewInt(builtin.ExpectedLeadersPerEpoch*builtin.EpochsInDay)),
+		mp.TotalPower.QualityAdjPower,
+	)
+	stats.Record(ctx, metrics.LocalExpectedWinsPerDay.M(perDay))
+
+	since, err := b.since(maddr)
+	if errors.Is(err, datastore.ErrNotFound) {
+		log.Debugw("miner not tracked yet, skip lucky value", "miner", maddr)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	history, err := b.History(maddr.String(), head.Height()-luckyWindows["30d"], 0)
+	if err != nil {
+		return err
+	}
+
+	for day, epochs := range luckyWindows {
+		from := head.Height() - epochs
+		//只统计 monitor 开始跟踪之后的部分
+		if since > from {
+			from = since
+		}
+		//blockHistory 比窗口短时，只统计保留了出块记录的部分
+		if b.dc.BlockHistory > 0 {
+			if start := head.Height() - b.historyEpochs(); start > from {
+				from = start
+			}
+		}
+		covered := head.Height() - from
+		if covered <= 0 {
+			continue
+		}
+
+		var observed int64
+		for _, r := range history {
+			if r.Status != StatusOnchain || r.Height <= from {
+				continue
+			}
+			if r.Reward != nil {
+				observed += r.Reward.WinCount
+			} else {
+				observed += 1
+			}
+		}
+		expected := perDay * float64(covered) / float64(builtin.EpochsInDay)
+
+		ctx, _ := tag.New(ctx,
+			tag.Upsert(metrics.LuckyValueDay, day),
+		)
+		stats.Record(ctx,
+			metrics.LocalExpectedWins.M(expected),
+			metrics.LocalObservedWins.M(observed),
+			metrics.LocalLuckyValue.M(float64(observed)/expected),
+		)
+	}
+
+	return nil
+}
+
+// since 返回开始跟踪 miner 出块的高度，第一次发现 miner 时记录
+func (b *Blocks) since(maddr address.Address) (abi.ChainEpoch, error) {
+	data, err := b.ds.Get(b.ctx, sincePrefix.ChildString(maddr.String()))
+	if err != nil {
+		return 0, err
+	}
+
+	h, err := strconv.ParseInt(string(data), 10, 64)
+	return abi.ChainEpoch(h), err
+}
+
+func (b *Blocks) trackSince(maddr address.Address, h abi.ChainEpoch) error {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	if _, ok := b.tracked[maddr]; ok {
+		return nil
+	}
+
+	key := sincePrefix.ChildString(maddr.String())
+	has, err := b.ds.Has(b.ctx, key)
+	if err != nil {
+		return err
+	}
+	if !has {
+		if err := b.ds.Put(b.ctx, key, []byte(strconv.FormatInt(int64(h), 10))); err != nil {
+			return err
+		}
+		log.Infow("start tracking miner blocks", "miner", maddr, "height", h)
+	}
+
+	b.tracked[maddr] = struct{}{}
+	return nil
+}
@@ -0,0 +1,135 @@
+package blocks
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+)
+
+// BlockChecker 查询区块浏览器是否收到了某个块，返回使用的 explorer
+type BlockChecker interface {
+	HasBlock(ctx context.Context, c cid.Cid) (bool, string, error)
+}
+
+// 孤块原因
+const (
+	CauseNullRound      = "null_round"      //主链该高度是空块
+	CauseStaleBase      = "stale_base"      //父 tipset 和主链不同，在过时的 base 上出块
+	CauseLate           = "late"            //提交太晚，其他节点没来得及收到
+	CauseNotPropagated  = "not_propagated"  //区块浏览器上找不到这个块，没有广播出去
+	CauseCompetingBlock = "competing_block" //相同 base 上其他块胜出
+)
+
+type CanonicalBlock struct {
+	Cid   string `json:"cid"`
+	Miner string `json:"miner"`
+}
+
+type OrphanAnalysis struct {
+	Cause            string           `json:"cause"`
+	NullRound        bool             `json:"nullRound"`
+	CanonicalHeight  abi.ChainEpoch   `json:"canonicalHeight"`
+	CanonicalBlocks  []CanonicalBlock `json:"canonicalBlocks"`
+	CanonicalParents []string         `json:"canonicalParents"`
+	Parents          []string         `json:"parents,omitempty"`
+	Propagated       bool             `json:"propagated"`
+	PropagationKnown bool             `json:"propagationKnown"` //区块浏览器查询成功
+	Explorer         string           `json:"explorer,omitempty"`
+	StaleBase        bool             `json:"staleBase"`
+	Delay            float64          `json:"delay"` //提交时间相对 epoch 开始时间的延迟（秒），只有上报的块才有
+	Late             bool             `json:"late"`
+}
+
+// analyseOrphan 和主链该高度的 tipset 对比，分析孤块原因
+func (b *Blocks) analyseOrphan(block Block, c cid.Cid, canonical *types.TipSet) (*OrphanAnalysis, error) {
+	api := b.dc.LotusApi
+
+	a := &OrphanAnalysis{
+		NullRound:       canonical.Height() < block.Height,
+		CanonicalHeight: canonical.Height(),
+	}
+	for _, bh := range canonical.Blocks() {
+		a.CanonicalBlocks = append(a.CanonicalBlocks, CanonicalBlock{Cid: bh.Cid().String(), Miner: bh.Miner.String()})
+	}
+	for _, p := range canonical.Parents().Cids() {
+		a.CanonicalParents = append(a.CanonicalParents, p.String())
+	}
+
+	//超过 lotus 的广播截止时间，其他节点已经开始打包下一个高度
+	threshold := b.dc.LateBlockThreshold.Seconds()
+	if threshold == 0 {
+		threshold = float64(build.PropagationDelaySecs)
+	}
+
+	//块头的 Timestamp 固定为 genesis + height*30，只能用 miner 上报的提交时间判断是否太晚
+	if block.Submitted > 0 {
+		genesis, err := b.genesisTime()
+		if err != nil {
+			return nil, err
+		}
+		epochStart := int64(genesis+uint64(block.Height)*builtin.EpochDurationSeconds) * 1000
+		a.Delay = float64(block.Submitted-epochStart) / 1000
+	}
+	//Took 为 miner 上报的出块耗时，链上发现的块没有
+	a.Late = a.Delay > threshold || block.Took > threshold
+
+	//本地 lotus 一定有自己出的块，只用来对比 parents
+	bh, err := api.ChainGetBlock(b.ctx, c)
+	if err == nil {
+		for _, p := range bh.Parents {
+			a.Parents = append(a.Parents, p.String())
+		}
+		if !a.NullRound {
+			a.StaleBase = types.NewTipSetKey(bh.Parents...) != canonical.Parents()
+		}
+	} else {
+		log.Debugw("ChainGetBlock failed", "cid", block.Cid, "err", err)
+	}
+
+	if b.checker != nil {
+		propagated, explorer, err := b.checker.HasBlock(b.ctx, c)
+		if err == nil {
+			a.Propagated = propagated
+			a.PropagationKnown = true
+			a.Explorer = explorer
+		} else {
+			log.Warnw("check block propagation failed", "cid", block.Cid, "err", err)
+		}
+	}
+
+	switch {
+	case a.NullRound:
+		a.Cause = CauseNullRound
+	case a.StaleBase:
+		a.Cause = CauseStaleBase
+	case a.Late:
+		a.Cause = CauseLate
+	case a.PropagationKnown && !a.Propagated:
+		a.Cause = CauseNotPropagated
+	default:
+		a.Cause = CauseCompetingBlock
+	}
+
+	return a, nil
+}
+
+func (b *Blocks) genesisTime() (uint64, error) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	if b.genesis != 0 {
+		return b.genesis, nil
+	}
+
+	g, err := b.dc.LotusApi.ChainGetGenesis(b.ctx)
+	if err != nil {
+		return 0, err
+	}
+	b.genesis = g.MinTimestamp()
+
+	return b.genesis, nil
+}
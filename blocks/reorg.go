@@ -0,0 +1,124 @@
+package blocks
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"go.opencensus.io/stats"
+)
+
+// 建议值按这个窗口内观察到的最大重组深度计算，很久以前的深度重组不会一直抬高建议值
+const reorgWindow = 24 * time.Hour
+
+type reorg struct {
+	at    time.Time
+	depth abi.ChainEpoch
+}
+
+// recordReorg ChainNotify 一批变化中先 revert 再 apply 即为一次链重组，
+// 深度为被 revert 的高度范围。OrphanCheckHeight 小于观察到的最大深度时，
+// 孤块检查可能在重组完成前就下结论，建议值为 reorgWindow 内最大深度 + 1，每次 head 变化都导出
+func (b *Blocks) recordReorg(reverts, applies []*types.TipSet) {
+	reverts = dropGrown(reverts, applies)
+
+	var depth, low, high abi.ChainEpoch
+	if len(reverts) > 0 {
+		low, high = reverts[0].Height(), reverts[0].Height()
+		for _, ts := range reverts {
+			if ts.Height() < low {
+				low = ts.Height()
+			}
+			if ts.Height() > high {
+				high = ts.Height()
+			}
+		}
+		depth = high - low + 1
+
+		stats.Record(b.ctx,
+			metrics.ChainReorgDepth.M(int64(depth)),
+			metrics.ChainRevertedTipSets.M(int64(len(reverts))),
+		)
+		b.reorgs = append(b.reorgs, reorg{at: time.Now(), depth: depth})
+	}
+
+	max := b.maxReorgDepth()
+	suggested := max + 1
+	stats.Record(b.ctx, metrics.SuggestedOrphanCheckHeight.M(int64(suggested)))
+
+	if depth == 0 {
+		return
+	}
+
+	log.Infow("chain reorg", "depth", depth, "reverted", len(reverts), "applied", len(applies), "from", low, "to", high)
+	if int(suggested) > b.dc.OrphanCheckHeight {
+		log.Warnw("orphanCheckHeight may be too small", "orphanCheckHeight", b.dc.OrphanCheckHeight, "maxReorgDepth", max, "suggested", suggested)
+	}
+
+	miners := map[address.Address]struct{}{}
+	for _, m := range b.dc.MinersList() {
+		miners[m] = struct{}{}
+	}
+	for _, ts := range reverts {
+		for _, bh := range ts.Blocks() {
+			if _, ok := miners[bh.Miner]; ok {
+				log.Warnw("reorg reverted our block", "cid", bh.Cid(), "miner", bh.Miner, "height", bh.Height, "depth", depth)
+			}
+		}
+	}
+	for _, ts := range applies {
+		for _, bh := range ts.Blocks() {
+			if _, ok := miners[bh.Miner]; ok {
+				log.Infow("reorg applied our block", "cid", bh.Cid(), "miner", bh.Miner, "height", bh.Height, "depth", depth)
+			}
+		}
+	}
+}
+
+// maxReorgDepth 返回 reorgWindow 内的最大重组深度，同时丢弃窗口外的记录
+func (b *Blocks) maxReorgDepth() abi.ChainEpoch {
+	cutoff := time.Now().Add(-reorgWindow)
+	var max abi.ChainEpoch
+	kept := b.reorgs[:0]
+	for _, r := range b.reorgs {
+		if r.at.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, r)
+		if r.depth > max {
+			max = r.depth
+		}
+	}
+	b.reorgs = kept
+	return max
+}
+
+// dropGrown 去掉同一高度上 tipset 增加了块的情况：被 revert 的块都在同高度 apply 的 tipset 中，
+// 没有块离开主链，不算重组
+func dropGrown(reverts, applies []*types.TipSet) []*types.TipSet {
+	var ret []*types.TipSet
+	for _, r := range reverts {
+		grown := false
+		for _, a := range applies {
+			if a.Height() == r.Height() && containsAll(a, r) {
+				grown = true
+				break
+			}
+		}
+		if !grown {
+			ret = append(ret, r)
+		}
+	}
+	return ret
+}
+
+func containsAll(ts, sub *types.TipSet) bool {
+	for _, c := range sub.Cids() {
+		if !ts.Contains(c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package blocks
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/reward"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/ipfs/go-cid"
+)
+
+type BlockReward struct {
+	WinCount    int64           `json:"winCount"`
+	BlockReward abi.TokenAmount `json:"blockReward"`
+	GasReward   abi.TokenAmount `json:"gasReward"`
+	Total       abi.TokenAmount `json:"total"`
+}
+
+// blockReward 计算上链块的出块奖励：
+// 出块奖励 = ThisEpochReward * WinCount / ExpectedLeadersPerEpoch
+// gas 奖励 = 块内（tipset 中之前的块没有包含的）消息的 GasLimit * 有效 GasPremium
+func (b *Blocks) blockReward(c cid.Cid, ts *types.TipSet) (*BlockReward, error) {
+	api := b.dc.LotusApi
+
+	var header *types.BlockHeader
+	for _, bh := range ts.Blocks() {
+		if bh.Cid() == c {
+			header = bh
+			break
+		}
+	}
+	if header == nil {
+		return nil, errNotInTipSet
+	}
+
+	act, err := api.StateGetActor(b.ctx, reward.Address, ts.Key())
+	if err != nil {
+		return nil, err
+	}
+	rs, err := reward.Load(b.store, act)
+	if err != nil {
+		return nil, err
+	}
+	epochReward, err := rs.ThisEpochReward()
+	if err != nil {
+		return nil, err
+	}
+
+	winCount := header.ElectionProof.WinCount
+	blockReward := big.Div(big.Mul(epochReward, big.NewInt(winCount)), big.NewInt(builtin.ExpectedLeadersPerEpoch))
+
+	gasReward, err := b.gasReward(c, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlockReward{
+		WinCount:    winCount,
+		BlockReward: blockReward,
+		GasReward:   gasReward,
+		Total:       big.Add(blockReward, gasReward),
+	}, nil
+}
+
+// gasReward tipset 中按顺序去重，重复的消息只有第一个包含它的块能拿到 tip
+func (b *Blocks) gasReward(c cid.Cid, ts *types.TipSet) (abi.TokenAmount, error) {
+	baseFee := ts.Blocks()[0].ParentBaseFee
+	seen := map[cid.Cid]struct{}{}
+	tip := big.Zero()
+
+	for _, bh := range ts.Blocks() {
+		bm, err := b.dc.LotusApi.ChainGetBlockMessages(b.ctx, bh.Cid())
+		if err != nil {
+			return big.Zero(), err
+		}
+
+		msgs := bm.BlsMessages
+		for _, sm := range bm.SecpkMessages {
+			msgs = append(msgs, sm.VMMessage())
+		}
+
+		for _, m := range msgs {
+			if _, ok := seen[m.Cid()]; ok {
+				continue
+			}
+			seen[m.Cid()] = struct{}{}
+
+			if bh.Cid() != c {
+				continue
+			}
+			premium := big.Min(m.GasPremium, big.Sub(m.GasFeeCap, baseFee))
+			if premium.LessThan(big.Zero()) {
+				continue
+			}
+			tip = big.Add(tip, big.Mul(premium, big.NewInt(m.GasLimit)))
+		}
+
+		if bh.Cid() == c {
+			break
+		}
+	}
+
+	return tip, nil
+}
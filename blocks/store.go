@@ -0,0 +1,241 @@
+package blocks
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+)
+
+var (
+	pendingPrefix  = datastore.NewKey("/pending")
+	resolvedPrefix = datastore.NewKey("/resolved")
+	lastHeightKey  = datastore.NewKey("/meta/lastHeight")
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusOnchain Status = "onchain"
+	StatusOrphan  Status = "orphan"
+)
+
+type BlockRecord struct {
+	Block
+	Status      Status         `json:"status"`
+	CheckHeight abi.ChainEpoch `json:"checkHeight,omitempty"`
+	//孤块原因分析，只有 orphan 状态才有
+	Orphan *OrphanAnalysis `json:"orphan,omitempty"`
+	//出块奖励，只有 onchain 状态才有
+	Reward *BlockReward `json:"reward,omitempty"`
+}
+
+// load 启动时从 datastore 恢复 pending 块和最后处理的高度
+func (b *Blocks) load() error {
+	res, err := b.ds.Query(b.ctx, query.Query{Prefix: pendingPrefix.String()})
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	for r := range res.Next() {
+		if r.Error != nil {
+			return r.Error
+		}
+		var block Block
+		if err := json.Unmarshal(r.Value, &block); err != nil {
+			return err
+		}
+		b.blocks[block.Cid] = block
+	}
+
+	data, err := b.ds.Get(b.ctx, lastHeightKey)
+	if err != nil && !errors.Is(err, datastore.ErrNotFound) {
+		return err
+	}
+	if err == nil {
+		h, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return err
+		}
+		b.last = abi.ChainEpoch(h)
+	}
+
+	log.Infow("loaded blocks", "pending", len(b.blocks), "lastHeight", b.last)
+	return nil
+}
+
+func (b *Blocks) putPending(block Block) error {
+	data, err := json.Marshal(&block)
+	if err != nil {
+		return err
+	}
+
+	return b.ds.Put(b.ctx, pendingPrefix.ChildString(block.Cid), data)
+}
+
+func (b *Blocks) putResolved(r BlockRecord) error {
+	data, err := json.Marshal(&r)
+	if err != nil {
+		return err
+	}
+
+	batch, err := b.ds.Batch(b.ctx)
+	if err != nil {
+		return err
+	}
+	if err := batch.Delete(b.ctx, pendingPrefix.ChildString(r.Cid)); err != nil {
+		return err
+	}
+	if err := batch.Put(b.ctx, resolvedPrefix.ChildString(r.Cid), data); err != nil {
+		return err
+	}
+
+	return batch.Commit(b.ctx)
+}
+
+// putReopened 删除 resolved 记录，重新放回 pending
+func (b *Blocks) putReopened(block Block) error {
+	data, err := json.Marshal(&block)
+	if err != nil {
+		return err
+	}
+
+	batch, err := b.ds.Batch(b.ctx)
+	if err != nil {
+		return err
+	}
+	if err := batch.Delete(b.ctx, resolvedPrefix.ChildString(block.Cid)); err != nil {
+		return err
+	}
+	if err := batch.Put(b.ctx, pendingPrefix.ChildString(block.Cid), data); err != nil {
+		return err
+	}
+
+	return batch.Commit(b.ctx)
+}
+
+func (b *Blocks) getResolved(blockCid string) (BlockRecord, error) {
+	data, err := b.ds.Get(b.ctx, resolvedPrefix.ChildString(blockCid))
+	if err != nil {
+		return BlockRecord{}, err
+	}
+
+	var r BlockRecord
+	err = json.Unmarshal(data, &r)
+	return r, err
+}
+
+func (b *Blocks) isResolved(blockCid string) (bool, error) {
+	return b.ds.Has(b.ctx, resolvedPrefix.ChildString(blockCid))
+}
+
+func (b *Blocks) putLastHeight(h abi.ChainEpoch) error {
+	return b.ds.Put(b.ctx, lastHeightKey, []byte(strconv.FormatInt(int64(h), 10)))
+}
+
+// Get 查询块的状态，找不到时返回 datastore.ErrNotFound
+func (b *Blocks) Get(blockCid string) (BlockRecord, error) {
+	b.lk.Lock()
+	block, ok := b.blocks[blockCid]
+	b.lk.Unlock()
+	if ok {
+		return BlockRecord{Block: block, Status: StatusPending}, nil
+	}
+
+	return b.getResolved(blockCid)
+}
+
+// History 按高度倒序返回 [from, to] 之间的块，miner 为空时返回所有 miner，to 为 0 时不限制
+func (b *Blocks) History(miner string, from, to abi.ChainEpoch) ([]BlockRecord, error) {
+	match := func(block Block) bool {
+		if miner != "" && block.Miner != miner {
+			return false
+		}
+		if block.Height < from {
+			return false
+		}
+		if to > 0 && block.Height > to {
+			return false
+		}
+		return true
+	}
+
+	var ret []BlockRecord
+
+	b.lk.Lock()
+	for _, block := range b.blocks {
+		if match(block) {
+			ret = append(ret, BlockRecord{Block: block, Status: StatusPending})
+		}
+	}
+	b.lk.Unlock()
+
+	res, err := b.ds.Query(b.ctx, query.Query{Prefix: resolvedPrefix.String()})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	for e := range res.Next() {
+		if e.Error != nil {
+			return nil, e.Error
+		}
+		var r BlockRecord
+		if err := json.Unmarshal(e.Value, &r); err != nil {
+			return nil, err
+		}
+		if match(r.Block) {
+			ret = append(ret, r)
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Height > ret[j].Height
+	})
+
+	return ret, nil
+}
+
+// pruneHistory 删除 before 高度之前已经检查过的块
+func (b *Blocks) pruneHistory(before abi.ChainEpoch) error {
+	res, err := b.ds.Query(b.ctx, query.Query{Prefix: resolvedPrefix.String()})
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	batch, err := b.ds.Batch(b.ctx)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	for e := range res.Next() {
+		if e.Error != nil {
+			return e.Error
+		}
+		var r BlockRecord
+		if err := json.Unmarshal(e.Value, &r); err != nil {
+			return err
+		}
+		if r.Height < before {
+			if err := batch.Delete(b.ctx, datastore.NewKey(e.Key)); err != nil {
+				return err
+			}
+			count++
+		}
+	}
+
+	if err := batch.Commit(b.ctx); err != nil {
+		return err
+	}
+
+	log.Debugw("pruned block history", "before", before, "count", count)
+	return nil
+}
@@ -0,0 +1,88 @@
+package blocks
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+)
+
+func TestBlockStore(t *testing.T) {
+	ctx := context.Background()
+	b := &Blocks{
+		ctx:    ctx,
+		ds:     dssync.MutexWrap(datastore.NewMapDatastore()),
+		blocks: make(map[string]Block),
+	}
+
+	pending := Block{Cid: "bafy2bzacepending", Miner: "t01000", Height: 100}
+	onchain := Block{Cid: "bafy2bzaceonchain", Miner: "t01000", Height: 90}
+	orphan := Block{Cid: "bafy2bzaceorphan", Miner: "t01001", Height: 95}
+	for _, block := range []Block{pending, onchain, orphan} {
+		if err := b.add(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.resolve(BlockRecord{Block: onchain, Status: StatusOnchain, CheckHeight: 93}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.resolve(BlockRecord{Block: orphan, Status: StatusOrphan, CheckHeight: 98}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.putLastHeight(101); err != nil {
+		t.Fatal(err)
+	}
+
+	//重启后只恢复 pending
+	restarted := &Blocks{
+		ctx:    ctx,
+		ds:     b.ds,
+		blocks: make(map[string]Block),
+	}
+	if err := restarted.load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(restarted.blocks) != 1 || restarted.last != 101 {
+		t.Fatalf("unexpected state after load: %v, last: %d", restarted.blocks, restarted.last)
+	}
+
+	//已检查过的块不会再次进入 pending
+	if err := restarted.add(onchain); err != nil {
+		t.Fatal(err)
+	}
+	if len(restarted.blocks) != 1 {
+		t.Fatalf("resolved block re-added: %v", restarted.blocks)
+	}
+
+	r, err := restarted.Get(orphan.Cid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Status != StatusOrphan || r.CheckHeight != 98 {
+		t.Fatalf("unexpected record: %+v", r)
+	}
+
+	all, err := restarted.History("", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Cid != pending.Cid || all[2].Cid != onchain.Cid {
+		t.Fatalf("unexpected history: %+v", all)
+	}
+
+	byMiner, err := restarted.History("t01000", 91, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byMiner) != 1 || byMiner[0].Status != StatusPending {
+		t.Fatalf("unexpected history: %+v", byMiner)
+	}
+
+	if err := restarted.pruneHistory(95); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := restarted.Get(onchain.Cid); err != datastore.ErrNotFound {
+		t.Fatalf("expected pruned, got: %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+// 全网链状态指标：head 高度、head 相对墙上时间的延迟、每个 tipset 的块数、
+// 空块率、base fee、全网算力、流通量、网络版本
+
+package chain
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/actors/adt"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/power"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/config"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/stats"
+)
+
+var log = logging.Logger("monitor/chain")
+
+const (
+	defaultInterval = time.Second * 30
+	//统计空块率和平均块数的窗口
+	windowEpochs = abi.ChainEpoch(builtin.EpochsInHour)
+)
+
+type epoch struct {
+	height abi.ChainEpoch
+	blocks int
+}
+
+type Chain struct {
+	ctx   context.Context
+	dc    *config.DynamicConfig
+	store adt.Store
+
+	genesis uint64
+	recent  []epoch //窗口内的非空 tipset，按高度升序
+	lastKey types.TipSetKey
+
+	//回填历史数据时 head 相对墙上时间的延迟没有意义，不记录
+	backfill bool
+}
+
+func NewChain(ctx context.Context, dc *config.DynamicConfig) *Chain {
+	return &Chain{
+		ctx:   ctx,
+		dc:    dc,
+		store: adt.WrapStore(ctx, cbor.NewCborStore(blockstore.NewAPIBlockstore(dc.LotusApi))),
+	}
+}
+
+// NewBackfillChain 用于历史数据回填
+func NewBackfillChain(ctx context.Context, dc *config.DynamicConfig) *Chain {
+	c := NewChain(ctx, dc)
+	c.backfill = true
+	return c
+}
+
+func (c *Chain) Run() {
+	interval := time.Duration(c.dc.RecordInterval.Chain)
+	if interval == 0 {
+		interval = defaultInterval
+	}
+
+	go func() {
+		c.record()
+		t := time.NewTicker(interval)
+		for {
+			select {
+			case <-t.C:
+				c.record()
+			case <-c.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (c *Chain) record() {
+	stop := metrics.Timer(c.ctx, "chain/record")
+	defer stop()
+
+	head, err := c.dc.LotusApi.ChainHead(c.ctx)
+	if err != nil {
+		log.Errorw("ChainHead failed", "err", err)
+		metrics.RecordError(c.ctx, "chain/record")
+		return
+	}
+
+	if err := c.Record(c.ctx, head); err != nil {
+		log.Errorw("chain record failed", "height", head.Height(), "err", err)
+		metrics.RecordError(c.ctx, "chain/record")
+	}
+}
+
+func (c *Chain) Name() string {
+	return "chain"
+}
+
+// Record 记录 ts 高度的链状态
+func (c *Chain) Record(ctx context.Context, ts *types.TipSet) error {
+	api := c.dc.LotusApi
+
+	if c.genesis == 0 {
+		g, err := api.ChainGetGenesis(ctx)
+		if err != nil {
+			return err
+		}
+		c.genesis = g.MinTimestamp()
+	}
+	if err := c.updateRecent(ctx, ts); err != nil {
+		return err
+	}
+	var blocks int
+	for _, e := range c.recent {
+		blocks += e.blocks
+	}
+	covered := windowEpochs
+	if ts.Height() < covered {
+		covered = ts.Height()
+	}
+	nullRate := 0.0
+	avgBlocks := 0.0
+	if covered > 0 {
+		nullRate = 1 - float64(len(c.recent))/float64(covered)
+		avgBlocks = float64(blocks) / float64(covered)
+	}
+
+	act, err := api.StateGetActor(ctx, power.Address, ts.Key())
+	if err != nil {
+		return err
+	}
+	ps, err := power.Load(c.store, act)
+	if err != nil {
+		return err
+	}
+	total, err := ps.TotalPower()
+	if err != nil {
+		return err
+	}
+
+	cs, err := api.StateVMCirculatingSupplyInternal(ctx, ts.Key())
+	if err != nil {
+		return err
+	}
+	nv, err := api.StateNetworkVersion(ctx, ts.Key())
+	if err != nil {
+		return err
+	}
+
+	log.Debugw("chain record", "height", ts.Height(), "blocks", len(ts.Blocks()), "nullRate", nullRate, "nv", nv)
+	if !c.backfill {
+		expected := time.Unix(int64(c.genesis+uint64(ts.Height())*builtin.EpochDurationSeconds), 0)
+		stats.Record(ctx, metrics.ChainHeadLag.M(time.Since(expected).Seconds()))
+	}
+	stats.Record(ctx,
+		metrics.ChainHeight.M(int64(ts.Height())),
+		metrics.ChainBlocksPerTipSet.M(int64(len(ts.Blocks()))),
+		metrics.ChainAvgBlocksPerEpoch.M(avgBlocks),
+		metrics.ChainNullRoundRate.M(nullRate),
+		metrics.ChainBaseFee.M(types.BigDivFloat(ts.Blocks()[0].ParentBaseFee, types.NewInt(1))),
+		metrics.ChainNetworkRawPower.M(types.BigDivFloat(total.RawBytePower, types.NewInt(1))),
+		metrics.ChainNetworkQAPower.M(types.BigDivFloat(total.QualityAdjPower, types.NewInt(1))),
+		metrics.ChainCirculatingSupply.M(types.BigDivFloat(cs.FilCirculating, types.FromFil(1))),
+		metrics.ChainNetworkVersion.M(int64(nv)),
+	)
+
+	return nil
+}
+
+// updateRecent 从 ts 往回走到上次记录的高度，保留窗口内的 tipset
+func (c *Chain) updateRecent(ctx context.Context, ts *types.TipSet) error {
+	from := ts.Height() - windowEpochs
+	var last abi.ChainEpoch = -1
+	if len(c.recent) > 0 {
+		last = c.recent[len(c.recent)-1].height
+	}
+	//ts 在上次记录之前（链重组或 backfill），或同一高度换了 tipset，重新统计
+	if ts.Height() < last || (ts.Height() == last && ts.Key() != c.lastKey) {
+		c.recent = nil
+		last = -1
+	}
+	c.lastKey = ts.Key()
+	if last < from {
+		last = from
+	}
+
+	var added []epoch
+	for cur := ts; cur.Height() > last; {
+		added = append(added, epoch{height: cur.Height(), blocks: len(cur.Blocks())})
+		if cur.Height() == 0 {
+			break
+		}
+
+		parent, err := c.dc.LotusApi.ChainGetTipSet(ctx, cur.Parents())
+		if err != nil {
+			return err
+		}
+		cur = parent
+	}
+
+	for i := len(added) - 1; i >= 0; i-- {
+		c.recent = append(c.recent, added[i])
+	}
+
+	i := 0
+	for i < len(c.recent) && c.recent[i].height <= from {
+		i++
+	}
+	c.recent = c.recent[i:]
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	cliutil "github.com/filecoin-project/lotus/cli/util"
+	"github.com/gh-efforts/lotus-monitor/backfill"
+	"github.com/gh-efforts/lotus-monitor/chain"
+	"github.com/gh-efforts/lotus-monitor/config"
+	"github.com/gh-efforts/lotus-monitor/fullnode"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli/v2"
+)
+
+var backfillCmd = &cli.Command{
+	Name:  "backfill",
+	Usage: "replay chain-derived collectors at past tipsets, output OpenMetrics text with timestamps",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "config",
+			Value: "./config.json",
+			Usage: "specify config file path",
+		},
+		&cli.Int64Flag{
+			Name:     "from",
+			Usage:    "start height",
+			Required: true,
+		},
+		&cli.Int64Flag{
+			Name:  "to",
+			Usage: "end height, default chain head",
+		},
+		&cli.Int64Flag{
+			Name:  "stride",
+			Value: builtin.EpochsInHour,
+			Usage: "epochs between two samples",
+		},
+		&cli.BoolFlag{
+			Name:  "wins",
+			Value: true,
+			Usage: "count wins of miners between samples, walks every tipset in range",
+		},
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "output file, default stdout",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		path, err := homedir.Expand(cctx.String("config"))
+		if err != nil {
+			return err
+		}
+
+		ctx := cliutil.ReqContext(cctx)
+		dc, err := config.NewDynamicConfig(ctx, path)
+		if err != nil {
+			return err
+		}
+
+		to := abi.ChainEpoch(cctx.Int64("to"))
+		if !cctx.IsSet("to") {
+			head, err := dc.LotusApi.ChainHead(ctx)
+			if err != nil {
+				return err
+			}
+			to = head.Height()
+		}
+		if to <= 0 {
+			return errors.New("invalid end height")
+		}
+
+		var w io.Writer = os.Stdout
+		if cctx.IsSet("output") {
+			f, err := os.Create(cctx.String("output"))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		b := backfill.NewBackfill(ctx, dc, "lotusmonitor",
+			fullnode.NewFullNode(ctx, dc),
+			chain.NewBackfillChain(ctx, dc),
+		)
+		return b.Run(backfill.Options{
+			From:   abi.ChainEpoch(cctx.Int64("from")),
+			To:     to,
+			Stride: abi.ChainEpoch(cctx.Int64("stride")),
+			Wins:   cctx.Bool("wins"),
+		}, w)
+	},
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/blocks"
+	"github.com/urfave/cli/v2"
+)
+
+var blocksCmd = &cli.Command{
+	Name:  "blocks",
+	Usage: "query mined blocks",
+	Subcommands: []*cli.Command{
+		blocksListCmd,
+		blocksStatusCmd,
+	},
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "connect",
+			Value: "127.0.0.1:6789",
+		},
+	},
+}
+
+var blocksListCmd = &cli.Command{
+	Name:  "list",
+	Usage: "list mined blocks",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "miner-id",
+			Usage: "only list blocks of this miner",
+		},
+		&cli.Int64Flag{
+			Name:  "from",
+			Usage: "start height",
+		},
+		&cli.Int64Flag{
+			Name:  "to",
+			Usage: "end height",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		q := url.Values{}
+		if cctx.IsSet("miner-id") {
+			q.Set("miner", cctx.String("miner-id"))
+		}
+		if cctx.IsSet("from") {
+			q.Set("from", fmt.Sprint(cctx.Int64("from")))
+		}
+		if cctx.IsSet("to") {
+			q.Set("to", fmt.Sprint(cctx.Int64("to")))
+		}
+
+		u := fmt.Sprintf("http://%s/blocks?%s", cctx.String("connect"), q.Encode())
+		resp, err := http.Get(u)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			r, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("status: %s msg: %s", resp.Status, string(r))
+		}
+
+		var records []blocks.BlockRecord
+		err = json.NewDecoder(resp.Body).Decode(&records)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "HEIGHT\tMINER\tCID\tSTATUS\tTOOK\tCHECK HEIGHT\tWIN COUNT\tREWARD")
+		for _, r := range records {
+			status := string(r.Status)
+			if r.Orphan != nil {
+				status = fmt.Sprintf("%s(%s)", r.Status, r.Orphan.Cause)
+			}
+			winCount, reward := "-", "-"
+			if r.Reward != nil {
+				winCount = fmt.Sprint(r.Reward.WinCount)
+				reward = types.FIL(r.Reward.Total).Short()
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%.2fs\t%d\t%s\t%s\n", r.Height, r.Miner, r.Cid, status, r.Took, r.CheckHeight, winCount, reward)
+		}
+
+		return tw.Flush()
+	},
+}
+
+var blocksStatusCmd = &cli.Command{
+	Name:      "status",
+	Usage:     "get status of a block",
+	ArgsUsage: "<block cid>",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return errors.New("must specify block cid")
+		}
+
+		u := fmt.Sprintf("http://%s/blocks/%s", cctx.String("connect"), cctx.Args().First())
+		resp, err := http.Get(u)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			r, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("status: %s msg: %s", resp.Status, string(r))
+		}
+
+		var record blocks.BlockRecord
+		err = json.NewDecoder(resp.Body).Decode(&record)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(&record, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+
+		return nil
+	},
+}
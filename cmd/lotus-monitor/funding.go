@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/control"
+	"github.com/urfave/cli/v2"
+)
+
+var fundingCmd = &cli.Command{
+	Name:  "funding",
+	Usage: "worker/control address funding",
+	Subcommands: []*cli.Command{
+		fundingPlanCmd,
+	},
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "connect",
+			Value: "127.0.0.1:6789",
+		},
+	},
+}
+
+var fundingPlanCmd = &cli.Command{
+	Name:  "plan",
+	Usage: "compute FIL needed by worker/control addresses to reach target days, nothing is sent",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "miner-id",
+			Usage: "only plan addresses of this miner",
+		},
+		&cli.IntFlag{
+			Name:  "days",
+			Usage: "target available days, default use targetDays in config",
+		},
+		&cli.StringFlag{
+			Name:  "from",
+			Usage: "source wallet, default use fundingSource in config",
+		},
+		&cli.StringFlag{
+			Name:  "output",
+			Value: "table",
+			Usage: "output format: table, lotus (unsigned lotus send commands), json (unsigned messages)",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		q := url.Values{}
+		if cctx.IsSet("miner-id") {
+			q.Set("miner", cctx.String("miner-id"))
+		}
+		if cctx.IsSet("days") {
+			q.Set("days", fmt.Sprint(cctx.Int("days")))
+		}
+
+		u := fmt.Sprintf("http://%s/funding/plan?%s", cctx.String("connect"), q.Encode())
+		resp, err := http.Get(u)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			r, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("status: %s msg: %s", resp.Status, string(r))
+		}
+
+		var plan control.FundingPlan
+		err = json.NewDecoder(resp.Body).Decode(&plan)
+		if err != nil {
+			return err
+		}
+
+		source := plan.Source
+		if cctx.IsSet("from") {
+			source = cctx.String("from")
+		}
+
+		switch cctx.String("output") {
+		case "table":
+			tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			fmt.Fprintf(tw, "target days: %d\n", plan.TargetDays)
+			fmt.Fprintln(tw, "MINER\tADDRESS\tTYPE\tBALANCE\tBURN/DAY\tDAYS\tNEEDED")
+			for _, i := range plan.Items {
+				days := "inf"
+				if i.Days != math.MaxFloat64 {
+					days = fmt.Sprintf("%.1f", i.Days)
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", i.Miner, i.Address, i.Type, types.FIL(i.Balance).Short(), types.FIL(i.BurnPerDay).Short(), days, types.FIL(i.Needed).Short())
+			}
+			return tw.Flush()
+		case "lotus":
+			if source == "" {
+				return errors.New("must specify --from or fundingSource in config")
+			}
+			for _, i := range plan.Items {
+				if i.Needed.IsZero() {
+					continue
+				}
+				fmt.Printf("lotus send --from %s %s %s\n", source, i.Address, types.FIL(i.Needed).Unitless())
+			}
+			return nil
+		case "json":
+			if source == "" {
+				return errors.New("must specify --from or fundingSource in config")
+			}
+			from, err := address.NewFromString(source)
+			if err != nil {
+				return err
+			}
+			msgs := []*types.Message{}
+			for _, i := range plan.Items {
+				if i.Needed.IsZero() {
+					continue
+				}
+				msgs = append(msgs, &types.Message{
+					To:         i.Address,
+					From:       from,
+					Value:      i.Needed,
+					Method:     builtin.MethodSend,
+					GasFeeCap:  big.Zero(),
+					GasPremium: big.Zero(),
+				})
+			}
+			data, err := json.MarshalIndent(msgs, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		default:
+			return fmt.Errorf("unknown output format: %s", cctx.String("output"))
+		}
+	},
+}
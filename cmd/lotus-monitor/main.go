@@ -19,13 +19,20 @@ import (
 	cliutil "github.com/filecoin-project/lotus/cli/util"
 	"github.com/gh-efforts/lotus-monitor/blocks"
 	"github.com/gh-efforts/lotus-monitor/build"
+	"github.com/gh-efforts/lotus-monitor/chain"
 	"github.com/gh-efforts/lotus-monitor/config"
 	"github.com/gh-efforts/lotus-monitor/control"
+	"github.com/gh-efforts/lotus-monitor/deals"
 	"github.com/gh-efforts/lotus-monitor/filfox"
 	"github.com/gh-efforts/lotus-monitor/fullnode"
+	"github.com/gh-efforts/lotus-monitor/messages"
 	"github.com/gh-efforts/lotus-monitor/metrics"
 	"github.com/gh-efforts/lotus-monitor/mpool"
+	"github.com/gh-efforts/lotus-monitor/notify"
+	"github.com/gh-efforts/lotus-monitor/repo"
 	"github.com/gh-efforts/lotus-monitor/storageminer"
+	"github.com/gh-efforts/lotus-monitor/termination"
+	"github.com/gh-efforts/lotus-monitor/verifreg"
 )
 
 var (
@@ -39,6 +46,10 @@ func main() {
 		runCmd,
 		reloadCmd,
 		minerCmd,
+		blocksCmd,
+		fundingCmd,
+		backfillCmd,
+		terminationCmd,
 		pprofCmd,
 	}
 
@@ -66,6 +77,11 @@ var runCmd = &cli.Command{
 			Value: "./config.json",
 			Usage: "specify config file path",
 		},
+		&cli.StringFlag{
+			Name:  "repo",
+			Value: "~/.lotus-monitor",
+			Usage: "specify repo path for local datastore",
+		},
 		&cli.BoolFlag{
 			Name:  "debug",
 			Value: false,
@@ -89,6 +105,16 @@ var runCmd = &cli.Command{
 			return err
 		}
 
+		repoPath, err := homedir.Expand(cctx.String("repo"))
+		if err != nil {
+			return err
+		}
+		ds, err := repo.OpenDatastore(repoPath)
+		if err != nil {
+			return err
+		}
+		defer ds.Close()
+
 		exporter, err := prometheus.NewExporter(prometheus.Options{
 			Namespace: "lotusmonitor",
 		})
@@ -107,17 +133,58 @@ var runCmd = &cli.Command{
 		}
 		stats.Record(ctx, metrics.Info.M(1))
 
-		fullnode.NewFullNode(ctx, dc).Run()
+		fn := fullnode.NewFullNode(ctx, dc)
+		ch := chain.NewChain(ctx, dc)
+		if dc.EventDriven.Enabled {
+			log.Infow("event-driven collection", "epochs", dc.EventDriven.Epochs)
+			n := notify.NewNotify(ctx, dc)
+			n.Register(fn)
+			n.Register(ch)
+			n.Run()
+		} else {
+			fn.Run()
+			ch.Run()
+		}
 		storageminer.NewStorageMiner(ctx, dc).Run()
-		filfox.NewFilFox(ctx, dc).Run()
-		control.NewControl(ctx, dc).Run()
+		ff, err := filfox.NewFilFox(ctx, dc)
+		if err != nil {
+			return err
+		}
+		ff.Run()
+		ctrl := control.NewControl(ctx, dc)
+		if err := ctrl.Run(); err != nil {
+			return err
+		}
 		mpool.NewMpool(ctx, dc).Run()
+		verifreg.NewVerifreg(ctx, dc).Run()
+		term := termination.NewTermination(ctx, dc)
+		term.Run()
+
+		b, err := blocks.NewBlocks(ctx, dc, ds, ff)
+		if err != nil {
+			return err
+		}
+
+		msgs, err := messages.NewMessages(ctx, dc, ds)
+		if err != nil {
+			return err
+		}
+
+		dl, err := deals.NewDeals(ctx, dc, ds)
+		if err != nil {
+			return err
+		}
+		dl.Run()
 
 		listen := cctx.String("listen")
 		log.Infow("monitor server", "listen", listen)
 
 		http.Handle("/metrics", exporter)
-		http.Handle("/blocks", blocks.NewBlocks(ctx, dc))
+		http.Handle("/blocks", b)
+		http.Handle("/blocks/", b)
+		http.Handle("/messages/cost", msgs)
+		http.Handle("/funding/plan", http.HandlerFunc(ctrl.FundingPlanHandle))
+		http.Handle("/termination/estimate", http.HandlerFunc(term.EstimateHandle))
 		http.Handle("/reload", http.HandlerFunc(dc.ReloadHandle))
 		http.Handle("/miner/add", http.HandlerFunc(dc.AddMinerHandle))
 		http.Handle("/miner/remove/", http.HandlerFunc(dc.RemoveMinerHandle))
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/termination"
+	"github.com/urfave/cli/v2"
+)
+
+var terminationCmd = &cli.Command{
+	Name:  "termination",
+	Usage: "sector termination cost",
+	Subcommands: []*cli.Command{
+		terminationEstimateCmd,
+	},
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "connect",
+			Value: "127.0.0.1:6789",
+		},
+	},
+}
+
+var terminationEstimateCmd = &cli.Command{
+	Name:  "estimate",
+	Usage: "estimate termination fee, pledge and lost rewards of sectors, nothing is sent",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "miner-id",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "sectors",
+			Usage: "sector numbers or ranges, e.g. 1,3,10-20, default all sectors",
+		},
+		&cli.StringFlag{
+			Name:  "output",
+			Value: "table",
+			Usage: "output format: table, json",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		q := url.Values{}
+		q.Set("miner", cctx.String("miner-id"))
+		if cctx.IsSet("sectors") {
+			//提前校验，避免把错误的格式发给服务端
+			if _, err := termination.ParseSectors(cctx.String("sectors")); err != nil {
+				return err
+			}
+			q.Set("sectors", cctx.String("sectors"))
+		}
+
+		u := fmt.Sprintf("http://%s/termination/estimate?%s", cctx.String("connect"), q.Encode())
+		resp, err := http.Get(u)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			r, err := io.ReadAll(resp.Body)
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("status: %s msg: %s", resp.Status, string(r))
+		}
+
+		var est termination.Estimate
+		err = json.NewDecoder(resp.Body).Decode(&est)
+		if err != nil {
+			return err
+		}
+
+		switch cctx.String("output") {
+		case "table":
+			tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			fmt.Fprintf(tw, "miner: %s height: %d\n", est.Miner, est.Height)
+			fmt.Fprintln(tw, "SECTOR\tEXPIRATION\tFEE\tPLEDGE\tLOST REWARDS")
+			for _, s := range est.Sectors {
+				fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t%s\n", s.Sector, s.Expiration, types.FIL(s.TerminationFee).Short(), types.FIL(s.Pledge).Short(), types.FIL(s.LostRewards).Short())
+			}
+			fmt.Fprintf(tw, "total %d\t\t%s\t%s\t%s\n", len(est.Sectors), types.FIL(est.TerminationFee).Short(), types.FIL(est.Pledge).Short(), types.FIL(est.LostRewards).Short())
+			if len(est.NotFound) > 0 {
+				fmt.Fprintf(tw, "not found or terminated: %v\n", est.NotFound)
+			}
+			return tw.Flush()
+		case "json":
+			data, err := json.MarshalIndent(est, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		default:
+			return fmt.Errorf("unknown output format: %s", cctx.String("output"))
+		}
+	},
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+const (
+	defaultCacheTTL = time.Hour
+	cacheSize       = 4096
+)
+
+type minerInfoKey struct {
+	miner address.Address
+	tsk   types.TipSetKey
+}
+
+// Cache 所有模块共用的缓存，减少对 lotus 的重复请求：
+// ID 地址和 robust 地址互相解析（TTL 过期），每个 tipset 的 MinerInfo。
+// EmptyTSK（head）的 MinerInfo 只缓存一个 epoch
+type Cache struct {
+	api api.FullNode
+
+	robust        *expirable.LRU[address.Address, address.Address]
+	ids           *expirable.LRU[address.Address, address.Address]
+	minerInfo     *expirable.LRU[minerInfoKey, api.MinerInfo]
+	headMinerInfo *expirable.LRU[address.Address, api.MinerInfo]
+}
+
+func NewCache(a api.FullNode, ttl time.Duration) *Cache {
+	if ttl == 0 {
+		ttl = defaultCacheTTL
+	}
+
+	return &Cache{
+		api:           a,
+		robust:        expirable.NewLRU[address.Address, address.Address](cacheSize, nil, ttl),
+		ids:           expirable.NewLRU[address.Address, address.Address](cacheSize, nil, ttl),
+		minerInfo:     expirable.NewLRU[minerInfoKey, api.MinerInfo](cacheSize, nil, ttl),
+		headMinerInfo: expirable.NewLRU[address.Address, api.MinerInfo](cacheSize, nil, time.Second*builtin.EpochDurationSeconds),
+	}
+}
+
+func recordCache(ctx context.Context, name string, hit bool) {
+	ctx, _ = tag.New(ctx,
+		tag.Upsert(metrics.CacheName, name),
+	)
+	if hit {
+		stats.Record(ctx, metrics.CacheHit.M(1))
+	} else {
+		stats.Record(ctx, metrics.CacheMiss.M(1))
+	}
+}
+
+// AccountKey ID 地址解析为 robust 地址
+func (c *Cache) AccountKey(ctx context.Context, addr address.Address) (address.Address, error) {
+	if addr.Protocol() != address.ID {
+		return addr, nil
+	}
+
+	if r, ok := c.robust.Get(addr); ok {
+		recordCache(ctx, "robust", true)
+		return r, nil
+	}
+	recordCache(ctx, "robust", false)
+
+	r, err := c.api.StateAccountKey(ctx, addr, types.EmptyTSK)
+	if err != nil {
+		return address.Undef, err
+	}
+	c.robust.Add(addr, r)
+	c.ids.Add(r, addr)
+
+	return r, nil
+}
+
+// LookupID robust 地址解析为 ID 地址
+func (c *Cache) LookupID(ctx context.Context, addr address.Address) (address.Address, error) {
+	if addr.Protocol() == address.ID {
+		return addr, nil
+	}
+
+	if id, ok := c.ids.Get(addr); ok {
+		recordCache(ctx, "id", true)
+		return id, nil
+	}
+	recordCache(ctx, "id", false)
+
+	id, err := c.api.StateLookupID(ctx, addr, types.EmptyTSK)
+	if err != nil {
+		return address.Undef, err
+	}
+	c.ids.Add(addr, id)
+	c.robust.Add(id, addr)
+
+	return id, nil
+}
+
+func (c *Cache) MinerInfo(ctx context.Context, maddr address.Address, tsk types.TipSetKey) (api.MinerInfo, error) {
+	if tsk == types.EmptyTSK {
+		if mi, ok := c.headMinerInfo.Get(maddr); ok {
+			recordCache(ctx, "miner_info", true)
+			return mi, nil
+		}
+	} else if mi, ok := c.minerInfo.Get(minerInfoKey{miner: maddr, tsk: tsk}); ok {
+		recordCache(ctx, "miner_info", true)
+		return mi, nil
+	}
+	recordCache(ctx, "miner_info", false)
+
+	mi, err := c.api.StateMinerInfo(ctx, maddr, tsk)
+	if err != nil {
+		return api.MinerInfo{}, err
+	}
+
+	if tsk == types.EmptyTSK {
+		c.headMinerInfo.Add(maddr, mi)
+	} else {
+		c.minerInfo.Add(minerInfoKey{miner: maddr, tsk: tsk}, mi)
+	}
+
+	return mi, nil
+}
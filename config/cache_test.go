@@ -0,0 +1,52 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/config"
+)
+
+type fakeNode struct {
+	api.FullNode
+	calls int
+}
+
+func (f *fakeNode) StateAccountKey(ctx context.Context, addr address.Address, tsk types.TipSetKey) (address.Address, error) {
+	f.calls++
+	return address.NewFromString("f1abjxfbp274xpdqcpuaykwkfb43omjotacm2p3za")
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	node := &fakeNode{}
+	c := config.NewCache(node, time.Minute)
+
+	id, _ := address.NewIDAddress(1000)
+	for i := 0; i < 3; i++ {
+		r, err := c.AccountKey(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Protocol() != address.SECP256K1 {
+			t.Fatalf("unexpected address: %s", r)
+		}
+	}
+	if node.calls != 1 {
+		t.Fatalf("expected 1 call, got %d", node.calls)
+	}
+
+	//反向解析命中缓存
+	r, _ := c.AccountKey(ctx, id)
+	got, err := c.LookupID(ctx, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != id {
+		t.Fatalf("unexpected id: %s", got)
+	}
+}
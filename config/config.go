@@ -16,6 +16,7 @@ import (
 	"github.com/filecoin-project/lotus/api/client"
 	"github.com/filecoin-project/lotus/api/v0api"
 	"github.com/filecoin-project/lotus/api/v1api"
+	"github.com/filecoin-project/lotus/build"
 	cliutil "github.com/filecoin-project/lotus/cli/util"
 	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
 	logging "github.com/ipfs/go-log/v2"
@@ -29,6 +30,10 @@ func (d Duration) MarshalJSON() ([]byte, error) {
 	return json.Marshal(time.Duration(d).String())
 }
 
+func (d Duration) Seconds() float64 {
+	return time.Duration(d).Seconds()
+}
+
 func (d *Duration) UnmarshalJSON(b []byte) error {
 	var s string
 	if err := json.Unmarshal(b, &s); err != nil {
@@ -54,16 +59,56 @@ type RecordInterval struct {
 	FilFox Duration `json:"filFox"`
 	Blocks Duration `json:"blocks"`
 	Mpool  Duration `json:"mpool"`
+	Chain  Duration `json:"chain"`
+
+	Verifreg    Duration `json:"verifreg"`
+	Deals       Duration `json:"deals"`
+	Termination Duration `json:"termination"` //估算所有扇区终止罚金的频率，为 0 时不统计
+	MpoolSync   Duration `json:"mpoolSync"`   //MpoolSub 之外，定期用 MpoolPending 全量同步
+}
+
+type ControlConfig struct {
+	Schedule   string `json:"schedule"`   //cron 表达式
+	Timezone   string `json:"timezone"`   //cron 使用的时区
+	BurnWindow int    `json:"burnWindow"` //统计消耗速度的天数
+
+	TargetDays    int    `json:"targetDays"`    //充值计划的目标可用天数
+	FundingSource string `json:"fundingSource"` //充值计划的转出钱包
+}
+
+type DealsConfig struct {
+	StartHeight int64    `json:"startHeight"` //首次运行从该高度开始索引 PublishStorageDeals，为 0 时从当前高度开始
+	NearStart   Duration `json:"nearStart"`   //未激活订单距离 StartEpoch 小于该时长时计入 near_start
+}
+
+type EventDrivenConfig struct {
+	Enabled bool `json:"enabled"`
+	Epochs  int  `json:"epochs"` //每隔几个高度采集一轮
 }
 
 type Config struct {
-	Lotus             []string                                           `json:"lotus"`
-	Miners            map[string]APIInfo                                 `json:"miners"`
-	Running           map[abi.SectorSize]map[sealtasks.TaskType]Duration `json:"running"`
-	RecordInterval    RecordInterval                                     `json:"recordInterval"`
-	FilFoxURL         string                                             `json:"filFoxURL"`
-	OrphanCheckHeight int                                                `json:"orphanCheckHeight"`
-	OrphanReset       Duration                                           `jsonn:"orphanReset"`
+	Lotus              []string                                           `json:"lotus"`
+	Miners             map[string]APIInfo                                 `json:"miners"`
+	Running            map[abi.SectorSize]map[sealtasks.TaskType]Duration `json:"running"`
+	RecordInterval     RecordInterval                                     `json:"recordInterval"`
+	FilFoxURL          string                                             `json:"filFoxURL"`
+	FilscanURL         string                                             `json:"filscanURL"`
+	Explorers          []string                                           `json:"explorers"`
+	ExplorerCache      Duration                                           `json:"explorerCache"`
+	OrphanCheckHeight  int                                                `json:"orphanCheckHeight"`
+	OrphanReset        Duration                                           `jsonn:"orphanReset"`
+	BlockHistory       Duration                                           `json:"blockHistory"`
+	LateBlockThreshold Duration                                           `json:"lateBlockThreshold"`
+	Control            ControlConfig                                      `json:"control"`
+	CacheTTL           Duration                                           `json:"cacheTTL"`
+	EventDriven        EventDrivenConfig                                  `json:"eventDriven"`
+	//claim 和 allocation 在几天内到期时计入 expiring
+	VerifregExpiringDays int `json:"verifregExpiringDays"`
+	//除已有 claim 的 client 外，还需要查询 allocation 的 client 地址
+	VerifregClients []string    `json:"verifregClients"`
+	Deals           DealsConfig `json:"deals"`
+	//owner/beneficiary 允许调用的方法，其他消息发出高危事件
+	SecurityAllowMethods []string `json:"securityAllowMethods"`
 }
 
 type MinerInfo struct {
@@ -81,12 +126,25 @@ type DynamicConfig struct {
 
 	LotusApi api.FullNode
 	closer   jsonrpc.ClientCloser
-
-	Running           map[abi.SectorSize]map[sealtasks.TaskType]Duration
-	RecordInterval    RecordInterval
-	FilFoxURL         string
-	OrphanCheckHeight int
-	OrphanReset       Duration
+	Cache    *Cache
+
+	Running            map[abi.SectorSize]map[sealtasks.TaskType]Duration
+	RecordInterval     RecordInterval
+	FilFoxURL          string
+	FilscanURL         string
+	Explorers          []string
+	ExplorerCache      Duration
+	OrphanCheckHeight  int
+	OrphanReset        Duration
+	BlockHistory       Duration
+	LateBlockThreshold Duration
+	Control            ControlConfig
+	EventDriven        EventDrivenConfig
+
+	VerifregExpiringDays int
+	VerifregClients      []string
+	Deals                DealsConfig
+	SecurityAllowMethods []string
 
 	lk     sync.RWMutex
 	miners map[address.Address]MinerInfo
@@ -179,18 +237,31 @@ func NewDynamicConfig(ctx context.Context, path string) (*DynamicConfig, error)
 	}
 
 	dc := &DynamicConfig{
-		ctx:               ctx,
-		cfg:               cfg,
-		path:              path,
-		reloadRequest:     make(chan struct{}, 10),
-		LotusApi:          a,
-		closer:            c,
-		Running:           cfg.Running,
-		RecordInterval:    cfg.RecordInterval,
-		FilFoxURL:         cfg.FilFoxURL,
-		OrphanCheckHeight: cfg.OrphanCheckHeight,
-		OrphanReset:       cfg.OrphanReset,
-		miners:            miners,
+		ctx:                ctx,
+		cfg:                cfg,
+		path:               path,
+		reloadRequest:      make(chan struct{}, 10),
+		LotusApi:           a,
+		closer:             c,
+		Cache:              NewCache(a, time.Duration(cfg.CacheTTL)),
+		Running:            cfg.Running,
+		RecordInterval:     cfg.RecordInterval,
+		FilFoxURL:          cfg.FilFoxURL,
+		FilscanURL:         cfg.FilscanURL,
+		Explorers:          cfg.Explorers,
+		ExplorerCache:      cfg.ExplorerCache,
+		OrphanCheckHeight:  cfg.OrphanCheckHeight,
+		OrphanReset:        cfg.OrphanReset,
+		BlockHistory:       cfg.BlockHistory,
+		LateBlockThreshold: cfg.LateBlockThreshold,
+		Control:            cfg.Control,
+		EventDriven:        cfg.EventDriven,
+
+		VerifregExpiringDays: cfg.VerifregExpiringDays,
+		VerifregClients:      cfg.VerifregClients,
+		Deals:                cfg.Deals,
+		SecurityAllowMethods: cfg.SecurityAllowMethods,
+		miners:               miners,
 	}
 	dc.watch()
 
@@ -454,16 +525,43 @@ func DefaultConfig() *Config {
 		FilFox: Duration(time.Hour),
 		Blocks: Duration(time.Minute),
 		Mpool:  Duration(time.Minute * 3),
+		Chain:  Duration(time.Second * 30),
+
+		Verifreg:  Duration(time.Minute * 10),
+		Deals:     Duration(time.Minute * 10),
+		MpoolSync: Duration(time.Minute * 30),
 	}
 
 	return &Config{
-		Lotus:             lotus,
-		Miners:            miners,
-		Running:           running,
-		RecordInterval:    interval,
-		FilFoxURL:         "https://calibration.filfox.info/api/v1", //mainnet: "https://filfox.info/api/v1"
-		OrphanCheckHeight: 3,
-		OrphanReset:       Duration(time.Minute * 2),
+		Lotus:              lotus,
+		Miners:             miners,
+		Running:            running,
+		RecordInterval:     interval,
+		FilFoxURL:          "https://calibration.filfox.info/api/v1", //mainnet: "https://filfox.info/api/v1"
+		FilscanURL:         "https://api-v2.filscan.io/api/v1",
+		Explorers:          []string{"filfox", "filscan"},
+		ExplorerCache:      Duration(time.Minute * 10),
+		OrphanCheckHeight:  3,
+		OrphanReset:        Duration(time.Minute * 2),
+		BlockHistory:       Duration(time.Hour * 24 * 30),
+		LateBlockThreshold: Duration(time.Second * time.Duration(build.PropagationDelaySecs)),
+		Control: ControlConfig{
+			Schedule:   "30 09 * * *",
+			Timezone:   "Asia/Shanghai",
+			BurnWindow: 3,
+			TargetDays: 30,
+		},
+		CacheTTL: Duration(time.Hour),
+		EventDriven: EventDrivenConfig{
+			Epochs: 1,
+		},
+
+		VerifregExpiringDays: 30,
+		VerifregClients:      []string{},
+		Deals: DealsConfig{
+			NearStart: Duration(time.Hour * 24),
+		},
+		SecurityAllowMethods: []string{"WithdrawBalance", "WithdrawBalanceExported"},
 	}
 }
 
@@ -1,17 +1,24 @@
-// 预估用来做windPost的control地址余额能用多少天。
-// 当前高度余额 / (昨天高度余额-当前高度余额）= 未来可用天数
+// 预估 worker/control 地址余额能用多少天。
+// 统计最近 burnWindow 天内从该地址发出的消息实际花费的 gas（不计转入），
+// 余额 / 每天消耗 = 未来可用天数
 
 package control
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/gh-efforts/lotus-monitor/config"
+	"github.com/gh-efforts/lotus-monitor/gas"
 	"github.com/gh-efforts/lotus-monitor/metrics"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/robfig/cron/v3"
@@ -21,26 +28,75 @@ import (
 
 var log = logging.Logger("monitor/control")
 
+const (
+	defaultSchedule   = "30 09 * * *"
+	defaultTimezone   = "Asia/Shanghai"
+	defaultBurnWindow = 3
+)
+
 type Control struct {
 	ctx context.Context
 	dc  *config.DynamicConfig
+
+	lk      sync.Mutex
+	status  map[address.Address][]AddressStatus
+	filling map[address.Address]struct{} //正在后台统计的 miner
+}
+
+// AddressStatus 最近一次统计的 worker/control 地址余额和消耗
+type AddressStatus struct {
+	Miner      address.Address `json:"miner"`
+	Address    address.Address `json:"address"`
+	Type       string          `json:"type"`
+	Balance    abi.TokenAmount `json:"balance"`
+	BurnPerDay abi.TokenAmount `json:"burnPerDay"`
+	Days       float64         `json:"days"`
+	Height     abi.ChainEpoch  `json:"height"`
 }
 
 func NewControl(ctx context.Context, dc *config.DynamicConfig) *Control {
 	return &Control{
-		ctx: ctx,
-		dc:  dc,
+		ctx:     ctx,
+		dc:      dc,
+		status:  make(map[address.Address][]AddressStatus),
+		filling: make(map[address.Address]struct{}),
 	}
 }
 
-func (c *Control) Run() {
-	//上海时间每天9:30执行一次
+// Run schedule 和 timezone 来自配置，格式错误时返回错误
+func (c *Control) Run() error {
+	//默认上海时间每天9:30执行一次
+	schedule := c.dc.Control.Schedule
+	if schedule == "" {
+		schedule = defaultSchedule
+	}
+	tz := c.dc.Control.Timezone
+	if tz == "" {
+		tz = defaultTimezone
+	}
+
 	r := cron.New()
-	_, err := r.AddFunc("CRON_TZ=Asia/Shanghai 30 09 * * *", func() { c.controlRecords() })
+	_, err := r.AddFunc("CRON_TZ="+tz+" "+schedule, func() { c.controlRecords() })
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("invalid control schedule %q or timezone %q: %w", schedule, tz, err)
 	}
 	r.Start()
+
+	//启动时依次统计一次，充值计划不需要等到第一次 cron
+	go func() {
+		for _, maddr := range c.dc.MinersList() {
+			c.fillOnce(maddr)
+		}
+	}()
+
+	return nil
+}
+
+func (c *Control) burnWindow() int {
+	if c.dc.Control.BurnWindow <= 0 {
+		return defaultBurnWindow
+	}
+	return c.dc.Control.BurnWindow
 }
 
 func (c *Control) controlRecords() {
@@ -49,12 +105,7 @@ func (c *Control) controlRecords() {
 
 	log.Debugw("cron controlRecords", "time", time.Now())
 
-	tody, err := c.dc.LotusApi.ChainHead(c.ctx)
-	if err != nil {
-		log.Error(err)
-		return
-	}
-	yesterday, err := c.dc.LotusApi.ChainGetTipSetByHeight(c.ctx, tody.Height()-abi.ChainEpoch(2880), types.EmptyTSK)
+	head, err := c.dc.LotusApi.ChainHead(c.ctx)
 	if err != nil {
 		log.Error(err)
 		return
@@ -62,47 +113,127 @@ func (c *Control) controlRecords() {
 
 	miners := c.dc.MinersList()
 	for _, maddr := range miners {
-		if err := c.controlRecord(maddr, tody.Key(), yesterday.Key()); err != nil {
+		if err := c.controlRecord(maddr, head); err != nil {
 			log.Errorw("controlRecord failed", "miner", maddr, "err", err)
 			metrics.RecordError(c.ctx, "control/controlRecord")
 		}
 	}
 }
 
-func (c *Control) controlRecord(maddr address.Address, tody, yesterday types.TipSetKey) error {
+func (c *Control) controlRecord(maddr address.Address, head *types.TipSet) error {
 	ctx, _ := tag.New(c.ctx,
 		tag.Upsert(metrics.MinerID, maddr.String()),
 	)
 
-	mi, err := c.dc.LotusApi.StateMinerInfo(ctx, maddr, tody)
+	mi, err := c.dc.Cache.MinerInfo(ctx, maddr, head.Key())
 	if err != nil {
 		return err
 	}
 
+	actors := map[address.Address]string{}
+	actors[mi.Worker] = "worker"
 	for _, ca := range mi.ControlAddresses {
-		actor, err := c.dc.LotusApi.StateGetActor(ctx, ca, tody)
+		actors[ca] = "control"
+	}
+
+	window := c.burnWindow()
+	from := head.Height() - abi.ChainEpoch(window)*builtin.EpochsInDay
+	baseFees := map[types.TipSetKey]abi.TokenAmount{}
+
+	var status []AddressStatus
+	for addr, typ := range actors {
+		actor, err := c.dc.LotusApi.StateGetActor(ctx, addr, head.Key())
 		if err != nil {
 			return err
 		}
-		actor2, err := c.dc.LotusApi.StateGetActor(ctx, ca, yesterday)
+		burn, err := c.burn(ctx, addr, head, from, baseFees)
 		if err != nil {
 			return err
 		}
 
-		var days float64
-		delta := types.BigSub(actor2.Balance, actor.Balance).Abs()
-		if delta.IsZero() {
-			days = math.MaxFloat64
-		} else {
-			days = types.BigDivFloat(actor.Balance, delta)
+		perDay := big.Div(burn, big.NewInt(int64(window)))
+		days := math.MaxFloat64
+		if !perDay.IsZero() {
+			days = types.BigDivFloat(actor.Balance, perDay)
 		}
 
-		ctx, _ = tag.New(ctx,
-			tag.Upsert(metrics.ActorAddress, ca.String()),
+		ctx, _ := tag.New(ctx,
+			tag.Upsert(metrics.ActorAddress, addr.String()),
+			tag.Upsert(metrics.AddressType, typ),
 		)
-		log.Debugw("controlRecord", "todyBalance", actor.Balance, "ydayBalance", actor2.Balance, "delta", delta, "days", days)
-		stats.Record(ctx, metrics.ControlDays.M(days))
+		log.Debugw("controlRecord", "address", addr, "type", typ, "balance", actor.Balance, "burn", burn, "perDay", perDay, "days", days)
+		stats.Record(ctx,
+			metrics.ControlDays.M(days),
+			metrics.ControlBurnPerDay.M(types.BigDivFloat(perDay, types.FromFil(1))),
+		)
+
+		status = append(status, AddressStatus{
+			Miner:      maddr,
+			Address:    addr,
+			Type:       typ,
+			Balance:    actor.Balance,
+			BurnPerDay: perDay,
+			Days:       days,
+			Height:     head.Height(),
+		})
 	}
 
+	c.lk.Lock()
+	c.status[maddr] = status
+	c.lk.Unlock()
+
 	return nil
 }
+
+// burn 统计 from 高度之后 addr 发出的消息实际支付的 gas 费用。
+// StateListMessages 按 From 精确匹配，所以 ID 地址和 robust 地址都要查
+func (c *Control) burn(ctx context.Context, addr address.Address, head *types.TipSet, from abi.ChainEpoch, baseFees map[types.TipSetKey]abi.TokenAmount) (abi.TokenAmount, error) {
+	a := c.dc.LotusApi
+
+	addrs := []address.Address{addr}
+	if key, err := c.dc.Cache.AccountKey(ctx, addr); err == nil {
+		if key != addr {
+			addrs = append(addrs, key)
+		}
+	} else {
+		log.Debugw("StateAccountKey failed", "address", addr, "err", err)
+	}
+
+	total := big.Zero()
+	for _, sender := range addrs {
+		cids, err := a.StateListMessages(ctx, &api.MessageMatch{From: sender}, head.Key(), from)
+		if err != nil {
+			return big.Zero(), err
+		}
+
+		for _, mc := range cids {
+			msg, err := a.ChainGetMessage(ctx, mc)
+			if err != nil {
+				return big.Zero(), err
+			}
+			lookup, err := a.StateSearchMsg(ctx, head.Key(), mc, api.LookbackNoLimit, true)
+			if err != nil {
+				return big.Zero(), err
+			}
+			if lookup == nil {
+				log.Debugw("message not found", "cid", mc)
+				continue
+			}
+
+			baseFee, ok := baseFees[lookup.TipSet]
+			if !ok {
+				ts, err := a.ChainGetTipSet(ctx, lookup.TipSet)
+				if err != nil {
+					return big.Zero(), err
+				}
+				baseFee = ts.Blocks()[0].ParentBaseFee
+				baseFees[lookup.TipSet] = baseFee
+			}
+
+			out := gas.ComputeOutputs(lookup.Receipt.GasUsed, msg.GasLimit, baseFee, msg.GasFeeCap, msg.GasPremium)
+			total = big.Add(total, out.Total())
+		}
+	}
+
+	return total, nil
+}
@@ -0,0 +1,153 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+)
+
+type FundingItem struct {
+	AddressStatus
+	Needed abi.TokenAmount `json:"needed"` //达到目标天数需要充值的 FIL
+}
+
+// FundingPlan 充值计划，只做计算，不会发送任何消息
+type FundingPlan struct {
+	Source     string        `json:"source"`
+	TargetDays int           `json:"targetDays"`
+	Items      []FundingItem `json:"items"`
+}
+
+// ErrNotReady miner 还没有统计过，统计需要扫描 burnWindow 天的消息，在后台进行
+var ErrNotReady = errors.New("control status not ready, retry later")
+
+// Plan 按最近一次统计的消耗速度计算每个 worker/control 地址达到 targetDays 需要的 FIL，
+// miner 为 address.Undef 时计算所有 miner；有 miner 还没有统计过时在后台统计并返回 ErrNotReady
+func (c *Control) Plan(miner address.Address, targetDays int) (*FundingPlan, error) {
+	if targetDays <= 0 {
+		targetDays = c.dc.Control.TargetDays
+	}
+
+	miners := c.dc.MinersList()
+	if miner != address.Undef {
+		miners = []address.Address{miner}
+	}
+
+	plan := &FundingPlan{
+		Source:     c.dc.Control.FundingSource,
+		TargetDays: targetDays,
+		Items:      []FundingItem{},
+	}
+	var missing []address.Address
+	for _, maddr := range miners {
+		c.lk.Lock()
+		status, ok := c.status[maddr]
+		c.lk.Unlock()
+
+		if !ok {
+			c.fill(maddr)
+			missing = append(missing, maddr)
+			continue
+		}
+
+		for _, s := range status {
+			needed := big.Sub(big.Mul(s.BurnPerDay, big.NewInt(int64(targetDays))), s.Balance)
+			if needed.LessThan(big.Zero()) {
+				needed = big.Zero()
+			}
+			plan.Items = append(plan.Items, FundingItem{AddressStatus: s, Needed: needed})
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %v", ErrNotReady, missing)
+	}
+
+	return plan, nil
+}
+
+// FundingPlanHandle GET /funding/plan?miner=&days=
+func (c *Control) FundingPlanHandle(w http.ResponseWriter, r *http.Request) {
+	log.Debugw("FundingPlanHandle", "path", r.URL.Path, "query", r.URL.RawQuery)
+
+	q := r.URL.Query()
+	miner := address.Undef
+	if m := q.Get("miner"); m != "" {
+		maddr, err := address.NewFromString(m)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		miner = maddr
+	}
+
+	var days int
+	if d := q.Get("days"); d != "" {
+		var err error
+		days, err = strconv.Atoi(d)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	plan, err := c.Plan(miner, days)
+	if errors.Is(err, ErrNotReady) {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(plan)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(data)
+}
+
+// fill 在后台统计一个 miner
+func (c *Control) fill(maddr address.Address) {
+	go c.fillOnce(maddr)
+}
+
+// fillOnce 统计一个还没有统计过的 miner，同一个 miner 同时只有一个在统计
+func (c *Control) fillOnce(maddr address.Address) {
+	c.lk.Lock()
+	_, done := c.status[maddr]
+	_, running := c.filling[maddr]
+	if done || running {
+		c.lk.Unlock()
+		return
+	}
+	c.filling[maddr] = struct{}{}
+	c.lk.Unlock()
+
+	defer func() {
+		c.lk.Lock()
+		delete(c.filling, maddr)
+		c.lk.Unlock()
+	}()
+
+	head, err := c.dc.LotusApi.ChainHead(c.ctx)
+	if err != nil {
+		log.Errorw("ChainHead failed", "err", err)
+		metrics.RecordError(c.ctx, "control/controlRecord")
+		return
+	}
+	if err := c.controlRecord(maddr, head); err != nil {
+		log.Errorw("controlRecord failed", "miner", maddr, "err", err)
+		metrics.RecordError(c.ctx, "control/controlRecord")
+	}
+}
@@ -0,0 +1,126 @@
+// 监控 miner 的市场订单：通过增量索引 PublishStorageDeals 消息发现订单，
+// 不需要全量 StateMarketDeals；按订单状态统计 active、已发布未激活、临近 StartEpoch 未激活和被 slash 的订单
+
+package deals
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/gh-efforts/lotus-monitor/config"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	"github.com/ipfs/go-datastore/query"
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("monitor/deals")
+
+var (
+	lastHeightKey = datastore.NewKey("/meta/lastHeight")
+	dealPrefix    = datastore.NewKey("/deal")
+)
+
+const (
+	statusPending = "pending"
+	statusActive  = "active"
+	statusSlashed = "slashed"
+)
+
+// Deal 索引到的订单，Start/End 来自 proposal
+type Deal struct {
+	ID       abi.DealID          `json:"id"`
+	Miner    string              `json:"miner"`
+	Size     abi.PaddedPieceSize `json:"size"`
+	Verified bool                `json:"verified"`
+	Start    abi.ChainEpoch      `json:"start"`
+	End      abi.ChainEpoch      `json:"end"`
+	Status   string              `json:"status"`
+}
+
+func dealKey(id abi.DealID) datastore.Key {
+	return dealPrefix.ChildString(strconv.FormatUint(uint64(id), 10))
+}
+
+type Deals struct {
+	ctx context.Context
+	dc  *config.DynamicConfig
+	ds  datastore.Batching
+
+	lk     sync.Mutex
+	last   abi.ChainEpoch
+	deals  map[abi.DealID]*Deal
+	cursor abi.DealID //active 订单轮流检查的位置
+}
+
+func NewDeals(ctx context.Context, dc *config.DynamicConfig, ds datastore.Batching) (*Deals, error) {
+	d := &Deals{
+		ctx:   ctx,
+		dc:    dc,
+		ds:    namespace.Wrap(ds, datastore.NewKey("/deals")),
+		deals: make(map[abi.DealID]*Deal),
+	}
+
+	data, err := d.ds.Get(ctx, lastHeightKey)
+	if err != nil && !errors.Is(err, datastore.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		h, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		d.last = abi.ChainEpoch(h)
+	}
+
+	res, err := d.ds.Query(ctx, query.Query{Prefix: dealPrefix.String()})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	for e := range res.Next() {
+		if e.Error != nil {
+			return nil, e.Error
+		}
+		var deal Deal
+		if err := json.Unmarshal(e.Value, &deal); err != nil {
+			return nil, err
+		}
+		d.deals[deal.ID] = &deal
+	}
+	log.Infow("loaded deals", "lastHeight", d.last, "deals", len(d.deals))
+
+	return d, nil
+}
+
+func (d *Deals) Run() {
+	d.watchChain()
+	d.runRecord()
+}
+
+func (d *Deals) put(deal *Deal) error {
+	data, err := json.Marshal(deal)
+	if err != nil {
+		return err
+	}
+	return d.ds.Put(d.ctx, dealKey(deal.ID), data)
+}
+
+func (d *Deals) remove(id abi.DealID) error {
+	d.lk.Lock()
+	delete(d.deals, id)
+	d.lk.Unlock()
+
+	return d.ds.Delete(d.ctx, dealKey(id))
+}
+
+func (d *Deals) lastHeight() abi.ChainEpoch {
+	d.lk.Lock()
+	defer d.lk.Unlock()
+	return d.last
+}
@@ -0,0 +1,47 @@
+package deals
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+)
+
+func TestCommitReload(t *testing.T) {
+	ctx := context.Background()
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	d, err := NewDeals(ctx, nil, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := []*Deal{
+		{ID: 1, Miner: "f01000", Size: 2048, Start: 200, End: 1000, Status: statusPending},
+		{ID: 2, Miner: "f01000", Size: 4096, Verified: true, Start: 300, End: 1000, Status: statusPending},
+	}
+	if err := d.commit(100, found); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.remove(1); err != nil {
+		t.Fatal(err)
+	}
+
+	//重启后从 datastore 恢复索引高度和订单
+	d, err = NewDeals(ctx, nil, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.lastHeight() != abi.ChainEpoch(100) {
+		t.Fatalf("lastHeight = %d, want 100", d.lastHeight())
+	}
+	if len(d.deals) != 1 {
+		t.Fatalf("deals = %d, want 1", len(d.deals))
+	}
+	deal := d.deals[2]
+	if deal == nil || deal.Size != 4096 || !deal.Verified || deal.Start != 300 {
+		t.Fatalf("unexpected deal %+v", deal)
+	}
+}
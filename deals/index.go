@@ -0,0 +1,208 @@
+package deals
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	markettypes "github.com/filecoin-project/go-state-types/builtin/v13/market"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/market"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/gh-efforts/lotus-monitor/notify"
+)
+
+// 每次最多向前索引的高度数，从 startHeight 追赶时分多轮完成
+const catchUpEpochs = policy.ChainFinality
+
+// watchChain 落后 head OrphanCheckHeight 个高度索引，避开链重组
+func (d *Deals) watchChain() {
+	notify.Watch(d.ctx, d.dc.LotusApi, "deals", func(changes []*api.HeadChange) {
+		head := notify.Head(changes)
+		if head == nil {
+			return
+		}
+
+		if err := d.index(head); err != nil {
+			log.Errorw("index failed", "height", head.Height(), "err", err)
+			metrics.RecordError(d.ctx, "deals/index")
+		}
+	})
+}
+
+// index 处理上次索引之后的 tipset，订单不能遗漏，落后太多时每次向前追赶 catchUpEpochs 个高度
+func (d *Deals) index(head *types.TipSet) error {
+	stop := metrics.Timer(d.ctx, "deals/index")
+	defer stop()
+
+	fapi := d.dc.LotusApi
+	target, err := notify.Behind(d.ctx, fapi, head, abi.ChainEpoch(d.dc.OrphanCheckHeight))
+	if err != nil {
+		return err
+	}
+
+	last := d.lastHeight()
+	if last == 0 {
+		last = target.Height() - 1
+		if start := abi.ChainEpoch(d.dc.Deals.StartHeight); start > 0 && start <= target.Height() {
+			last = start - 1
+		}
+	}
+	if target.Height() <= last {
+		return nil
+	}
+	if target.Height()-last > catchUpEpochs {
+		ts, err := fapi.ChainGetTipSetAfterHeight(d.ctx, last+catchUpEpochs, target.Key())
+		if err != nil {
+			return err
+		}
+		target = ts
+	}
+
+	var tss []*types.TipSet
+	for ts := target; ts.Height() > last; {
+		tss = append(tss, ts)
+
+		parent, err := fapi.ChainGetTipSet(d.ctx, ts.Parents())
+		if err != nil {
+			return err
+		}
+		ts = parent
+	}
+
+	providers, err := d.providers()
+	if err != nil {
+		return err
+	}
+
+	for i := len(tss) - 1; i >= 0; i-- {
+		if err := d.processTipSet(tss[i], providers); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// providers 监控 miner 的 ID 地址，proposal 中的 Provider 是 ID 地址
+func (d *Deals) providers() (map[address.Address]string, error) {
+	providers := map[address.Address]string{}
+	for _, maddr := range d.dc.MinersList() {
+		id, err := d.dc.Cache.LookupID(d.ctx, maddr)
+		if err != nil {
+			return nil, err
+		}
+		providers[id] = maddr.String()
+	}
+	return providers, nil
+}
+
+func (d *Deals) processTipSet(ts *types.TipSet, providers map[address.Address]string) error {
+	msgs, rcpts, err := notify.ParentMessages(d.ctx, d.dc.LotusApi, ts)
+	if err != nil {
+		return err
+	}
+
+	var found []*Deal
+	for i, pm := range msgs {
+		msg := pm.Message
+		if msg.To != market.Address || msg.Method != builtin.MethodsMarket.PublishStorageDeals || rcpts[i].ExitCode.IsError() {
+			continue
+		}
+
+		var params markettypes.PublishStorageDealsParams
+		if err := params.UnmarshalCBOR(bytes.NewReader(msg.Params)); err != nil {
+			log.Warnw("decode PublishStorageDeals params failed", "cid", pm.Cid, "err", err)
+			continue
+		}
+
+		var ours bool
+		for _, p := range params.Deals {
+			if _, ok := providers[p.Proposal.Provider]; ok {
+				ours = true
+				break
+			}
+		}
+		if !ours {
+			continue
+		}
+
+		nv, err := d.dc.LotusApi.StateNetworkVersion(d.ctx, ts.Parents())
+		if err != nil {
+			return err
+		}
+		ret, err := market.DecodePublishStorageDealsReturn(rcpts[i].Return, nv)
+		if err != nil {
+			return err
+		}
+		ids, err := ret.DealIDs()
+		if err != nil {
+			return err
+		}
+
+		for j, p := range params.Deals {
+			miner, ok := providers[p.Proposal.Provider]
+			if !ok {
+				continue
+			}
+			valid, idx, err := ret.IsDealValid(uint64(j))
+			if err != nil {
+				return err
+			}
+			if !valid || idx >= len(ids) {
+				continue
+			}
+
+			found = append(found, &Deal{
+				ID:       ids[idx],
+				Miner:    miner,
+				Size:     p.Proposal.PieceSize,
+				Verified: p.Proposal.VerifiedDeal,
+				Start:    p.Proposal.StartEpoch,
+				End:      p.Proposal.EndEpoch,
+				Status:   statusPending,
+			})
+		}
+	}
+
+	return d.commit(ts.Height(), found)
+}
+
+// commit 新订单和处理到的高度一起写入
+func (d *Deals) commit(h abi.ChainEpoch, found []*Deal) error {
+	batch, err := d.ds.Batch(d.ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, deal := range found {
+		data, err := json.Marshal(deal)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(d.ctx, dealKey(deal.ID), data); err != nil {
+			return err
+		}
+		log.Infow("deal published", "id", deal.ID, "miner", deal.Miner, "size", deal.Size, "verified", deal.Verified, "start", deal.Start)
+	}
+	if err := batch.Put(d.ctx, lastHeightKey, []byte(strconv.FormatInt(int64(h), 10))); err != nil {
+		return err
+	}
+	if err := batch.Commit(d.ctx); err != nil {
+		return err
+	}
+
+	d.lk.Lock()
+	for _, deal := range found {
+		d.deals[deal.ID] = deal
+	}
+	d.last = h
+	d.lk.Unlock()
+
+	return nil
+}
@@ -0,0 +1,265 @@
+package deals
+
+import (
+	"sort"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/actors/adt"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/market"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+const (
+	defaultInterval  = time.Minute * 10
+	defaultNearStart = time.Hour * 24
+	//每轮检查的 active 订单数，轮流检查是否被 slash
+	activeCheckBatch = 500
+)
+
+type summary struct {
+	count int64
+	size  int64
+}
+
+func (s *summary) add(size abi.PaddedPieceSize) {
+	s.count++
+	s.size += int64(size)
+}
+
+type minerStat struct {
+	active    summary
+	pending   summary
+	nearStart summary
+	slashed   summary
+}
+
+func (d *Deals) runRecord() {
+	interval := time.Duration(d.dc.RecordInterval.Deals)
+	if interval == 0 {
+		interval = defaultInterval
+	}
+
+	go func() {
+		d.record()
+		t := time.NewTicker(interval)
+		for {
+			select {
+			case <-t.C:
+				d.record()
+			case <-d.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (d *Deals) record() {
+	stop := metrics.Timer(d.ctx, "deals/record")
+	defer stop()
+
+	head, err := d.dc.LotusApi.ChainHead(d.ctx)
+	if err != nil {
+		log.Errorw("ChainHead failed", "err", err)
+		metrics.RecordError(d.ctx, "deals/record")
+		return
+	}
+
+	d.update(head)
+	d.recordStats(head.Height())
+}
+
+// update pending 订单每轮检查是否激活，active 订单每轮检查一批，到期的订单删除
+func (d *Deals) update(head *types.TipSet) {
+	h := head.Height()
+
+	d.lk.Lock()
+	var pending, active, finished []abi.DealID
+	for id, deal := range d.deals {
+		switch {
+		case deal.End <= h:
+			finished = append(finished, id)
+		case deal.Status == statusPending:
+			pending = append(pending, id)
+		case deal.Status == statusActive:
+			active = append(active, id)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i] < active[j] })
+	i := sort.Search(len(active), func(i int) bool { return active[i] >= d.cursor })
+	batch := make([]abi.DealID, 0, len(active))
+	batch = append(batch, active[i:]...)
+	batch = append(batch, active[:i]...)
+	if len(batch) > activeCheckBatch {
+		batch = batch[:activeCheckBatch]
+	}
+	if len(batch) > 0 {
+		d.cursor = batch[len(batch)-1] + 1
+	}
+	d.lk.Unlock()
+
+	for _, id := range finished {
+		if err := d.remove(id); err != nil {
+			log.Errorw("remove deal failed", "id", id, "err", err)
+		}
+	}
+
+	if len(pending)+len(batch) == 0 {
+		return
+	}
+	proposals, states, err := d.marketState(head)
+	if err != nil {
+		log.Errorw("load market state failed", "height", h, "err", err)
+		metrics.RecordError(d.ctx, "deals/marketState")
+		return
+	}
+
+	for _, id := range append(pending, batch...) {
+		if err := d.check(id, head, proposals, states); err != nil {
+			log.Errorw("check deal failed", "id", id, "err", err)
+			metrics.RecordError(d.ctx, "deals/check")
+		}
+	}
+}
+
+func (d *Deals) marketState(head *types.TipSet) (market.DealProposals, market.DealStates, error) {
+	act, err := d.dc.LotusApi.StateGetActor(d.ctx, market.Address, head.Key())
+	if err != nil {
+		return nil, nil, err
+	}
+	store := adt.WrapStore(d.ctx, cbor.NewCborStore(blockstore.NewAPIBlockstore(d.dc.LotusApi)))
+	mst, err := market.Load(store, act)
+	if err != nil {
+		return nil, nil, err
+	}
+	proposals, err := mst.Proposals()
+	if err != nil {
+		return nil, nil, err
+	}
+	states, err := mst.States()
+	if err != nil {
+		return nil, nil, err
+	}
+	return proposals, states, nil
+}
+
+// check 订单过期或扇区终止后 proposal 会被市场 actor 删除，未激活的订单没有 deal state
+func (d *Deals) check(id abi.DealID, head *types.TipSet, proposals market.DealProposals, states market.DealStates) error {
+	d.lk.Lock()
+	deal, ok := d.deals[id]
+	if !ok {
+		d.lk.Unlock()
+		return nil
+	}
+	cp := *deal
+	d.lk.Unlock()
+
+	_, hasProposal, err := proposals.Get(id)
+	if err != nil {
+		return err
+	}
+	st, hasState, err := states.Get(id)
+	if err != nil {
+		return err
+	}
+
+	status := cp.Status
+	switch {
+	case !hasProposal && cp.Status == statusPending:
+		if head.Height() < cp.Start {
+			return nil
+		}
+		//过了 StartEpoch 仍未激活，订单被市场 actor 删除
+		log.Warnw("deal missed start epoch", "id", id, "miner", cp.Miner, "size", cp.Size, "start", cp.Start)
+		ctx, _ := tag.New(d.ctx,
+			tag.Upsert(metrics.MinerID, cp.Miner),
+		)
+		stats.Record(ctx, metrics.DealsMissed.M(1))
+		return d.remove(id)
+	case !hasProposal:
+		//未到 EndEpoch 的 active 订单被删除，扇区已终止
+		status = statusSlashed
+	case !hasState:
+		//proposal 存在但还没有 deal state，未激活
+	case st.SlashEpoch() != -1:
+		status = statusSlashed
+	case st.SectorStartEpoch() > 0:
+		status = statusActive
+	}
+
+	if status == cp.Status {
+		return nil
+	}
+	if status == statusSlashed {
+		log.Warnw("deal slashed", "id", id, "miner", cp.Miner, "size", cp.Size, "verified", cp.Verified)
+	} else {
+		log.Infow("deal activated", "id", id, "miner", cp.Miner, "sectorStart", st.SectorStartEpoch())
+	}
+
+	cp.Status = status
+	if err := d.put(&cp); err != nil {
+		return err
+	}
+	d.lk.Lock()
+	if _, ok := d.deals[id]; ok {
+		d.deals[id] = &cp
+	}
+	d.lk.Unlock()
+	return nil
+}
+
+func (d *Deals) recordStats(h abi.ChainEpoch) {
+	nearStart := time.Duration(d.dc.Deals.NearStart)
+	if nearStart == 0 {
+		nearStart = defaultNearStart
+	}
+	near := h + abi.ChainEpoch(nearStart/(builtin.EpochDurationSeconds*time.Second))
+
+	stat := map[string]*minerStat{}
+	for _, maddr := range d.dc.MinersList() {
+		stat[maddr.String()] = &minerStat{}
+	}
+
+	d.lk.Lock()
+	for _, deal := range d.deals {
+		s, ok := stat[deal.Miner]
+		if !ok || deal.End <= h {
+			continue
+		}
+		switch deal.Status {
+		case statusActive:
+			s.active.add(deal.Size)
+		case statusSlashed:
+			s.slashed.add(deal.Size)
+		case statusPending:
+			s.pending.add(deal.Size)
+			if deal.Start <= near {
+				s.nearStart.add(deal.Size)
+			}
+		}
+	}
+	d.lk.Unlock()
+
+	for miner, s := range stat {
+		ctx, _ := tag.New(d.ctx,
+			tag.Upsert(metrics.MinerID, miner),
+		)
+		log.Debugw("deals record", "miner", miner, "active", s.active.count, "pending", s.pending.count, "nearStart", s.nearStart.count, "slashed", s.slashed.count)
+		stats.Record(ctx,
+			metrics.DealsActive.M(s.active.count),
+			metrics.DealsActiveSize.M(s.active.size),
+			metrics.DealsPending.M(s.pending.count),
+			metrics.DealsPendingSize.M(s.pending.size),
+			metrics.DealsNearStart.M(s.nearStart.count),
+			metrics.DealsNearStartSize.M(s.nearStart.size),
+			metrics.DealsSlashed.M(s.slashed.count),
+			metrics.DealsSlashedSize.M(s.slashed.size),
+		)
+	}
+}
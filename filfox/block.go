@@ -0,0 +1,24 @@
+package filfox
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ipfs/go-cid"
+)
+
+// HasBlock 按配置顺序依次查询 explorer，返回是否收到了这个块和使用的 explorer
+func (f *FilFox) HasBlock(ctx context.Context, c cid.Cid) (bool, string, error) {
+	var errs []error
+	for _, e := range f.explorers {
+		ok, err := e.HasBlock(ctx, c)
+		if err == nil {
+			return ok, e.Name(), nil
+		}
+
+		log.Warnw("explorer failed", "explorer", e.Name(), "block", c, "err", err)
+		errs = append(errs, err)
+	}
+
+	return false, "", errors.Join(errs...)
+}
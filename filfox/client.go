@@ -0,0 +1,163 @@
+package filfox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTooManyRequests = errors.New("429 Too Many Requests")
+	ErrNotFound        = errors.New("404 Not Found")
+)
+
+type rateLimit struct {
+	limit     int
+	remaining int
+	reset     int
+}
+
+type cacheEntry struct {
+	body    []byte
+	expires time.Time
+}
+
+// Client 所有 explorer 共用的 HTTP client，
+// 按 host 用 token bucket 限流（同步 x-ratelimit-* 响应头），并缓存响应
+type Client struct {
+	client http.Client
+	ttl    time.Duration
+
+	lk      sync.Mutex
+	cache   map[string]cacheEntry
+	buckets map[string]*tokenBucket
+}
+
+func NewClient(ttl time.Duration) *Client {
+	return &Client{
+		client:  http.Client{Timeout: time.Minute},
+		ttl:     ttl,
+		cache:   make(map[string]cacheEntry),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+func (c *Client) Get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req, url)
+}
+
+func (c *Client) Post(ctx context.Context, url string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, url+string(body))
+}
+
+func (c *Client) do(req *http.Request, key string) ([]byte, error) {
+	if body, ok := c.cached(key); ok {
+		log.Debugw("explorer cache hit", "key", key)
+		return body, nil
+	}
+
+	bucket := c.bucket(req.URL.Host)
+	if err := bucket.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	log.Debugw("explorer request", "method", req.Method, "url", req.URL)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if rl, err := parseRateLimit(resp.Header); err == nil {
+		bucket.update(rl)
+	} else {
+		log.Debugw("parseRateLimit failed", "host", req.URL.Host, "err", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.StatusCode == http.StatusTooManyRequests {
+			log.Warnw("429 Too Many Requests, block one minute", "host", req.URL.Host)
+			bucket.block(time.Minute)
+			return nil, ErrTooManyRequests
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, errors.New(resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lk.Lock()
+	c.cache[key] = cacheEntry{body: body, expires: time.Now().Add(c.ttl)}
+	c.lk.Unlock()
+
+	return body, nil
+}
+
+func (c *Client) cached(key string) ([]byte, bool) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	for k, e := range c.cache {
+		if time.Now().After(e.expires) {
+			delete(c.cache, k)
+		}
+	}
+
+	e, ok := c.cache[key]
+	return e.body, ok
+}
+
+func (c *Client) bucket(host string) *tokenBucket {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	b, ok := c.buckets[host]
+	if !ok {
+		b = newTokenBucket()
+		c.buckets[host] = b
+	}
+	return b
+}
+
+func parseRateLimit(header http.Header) (rateLimit, error) {
+	log.Debug(header)
+	limit, err := strconv.Atoi(header.Get("x-ratelimit-limit"))
+	if err != nil {
+		return rateLimit{}, err
+	}
+	remaining, err := strconv.Atoi(header.Get("x-ratelimit-remaining"))
+	if err != nil {
+		return rateLimit{}, err
+	}
+	reset, err := strconv.Atoi(header.Get("x-ratelimit-reset"))
+	if err != nil {
+		return rateLimit{}, err
+	}
+
+	r := rateLimit{
+		limit:     limit,
+		remaining: remaining,
+		reset:     reset,
+	}
+	log.Debug(r)
+	return r, nil
+}
@@ -0,0 +1,77 @@
+package filfox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+)
+
+// Explorer 区块浏览器后端，返回 miner 在 duration(1d/7d/30d) 内的挖矿统计，
+// HasBlock 查询区块浏览器是否收到了某个块，用来判断块有没有广播出去
+type Explorer interface {
+	Name() string
+	MiningStats(ctx context.Context, miner, duration string) (*MiningStats, error)
+	HasBlock(ctx context.Context, c cid.Cid) (bool, error)
+}
+
+// MiningStats 各 explorer 提供的字段不同，没有提供的字段为 nil 或空字符串，不导出
+type MiningStats struct {
+	RawBytePowerGrowth    string   `json:"rawBytePowerGrowth"`
+	QualityAdjPowerGrowth string   `json:"qualityAdjPowerGrowth"`
+	RawBytePowerDelta     string   `json:"rawBytePowerDelta"`
+	QualityAdjPowerDelta  string   `json:"qualityAdjPowerDelta"`
+	BlocksMined           *int     `json:"blocksMined"`
+	WeightedBlocksMined   *int     `json:"weightedBlocksMined"`
+	TotalRewards          string   `json:"totalRewards"`
+	NetworkTotalRewards   string   `json:"networkTotalRewards"`
+	EquivalentMiners      *float64 `json:"equivalentMiners"`
+	RewardPerByte         *float64 `json:"rewardPerByte"`
+	LuckyValue            *float64 `json:"luckyValue"`
+	DurationPercentage    *int     `json:"durationPercentage"`
+}
+
+func NewExplorer(name, url string, client *Client) (Explorer, error) {
+	switch name {
+	case "filfox":
+		return &filFoxExplorer{url: url, client: client}, nil
+	case "filscan":
+		return &filscanExplorer{url: url, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown explorer: %s", name)
+	}
+}
+
+type filFoxExplorer struct {
+	url    string
+	client *Client
+}
+
+func (e *filFoxExplorer) Name() string {
+	return "filfox"
+}
+
+func (e *filFoxExplorer) MiningStats(ctx context.Context, miner, duration string) (*MiningStats, error) {
+	url := fmt.Sprintf("%s/address/%s/mining-stats?duration=%s", e.url, miner, duration)
+	body, err := e.client.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	var res MiningStats
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (e *filFoxExplorer) HasBlock(ctx context.Context, c cid.Cid) (bool, error) {
+	_, err := e.client.Get(ctx, fmt.Sprintf("%s/block/%s", e.url, c))
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,137 @@
+package filfox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+)
+
+func TestFilFoxExplorer(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/address/f01000/mining-stats" || r.URL.Query().Get("duration") != "7d" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("x-ratelimit-limit", "60")
+		w.Header().Set("x-ratelimit-remaining", "59")
+		w.Header().Set("x-ratelimit-reset", "60")
+		w.Write([]byte(`{"blocksMined":3,"weightedBlocksMined":4,"totalRewards":"1000000000000000000","luckyValue":1.25}`))
+	}))
+	defer srv.Close()
+
+	e, err := NewExplorer("filfox", srv.URL, NewClient(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := e.MiningStats(context.Background(), "f01000", "7d")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *res.LuckyValue != 1.25 || *res.WeightedBlocksMined != 4 || res.EquivalentMiners != nil {
+			t.Fatalf("unexpected mining stats: %+v", res)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("expected cached response, got %d requests", requests)
+	}
+}
+
+func TestFilscanExplorer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			AccountID string            `json:"account_id"`
+			Filters   map[string]string `json:"filters"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path != "/IndicatorsByAccountID" || req.AccountID != "f01000" || req.Filters["interval"] != "24h" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"result":{"miner_indicators":{"block_count":2,"win_count":"3","block_reward":"2000000000000000000","lucky_rate":"0.85"}}}`))
+	}))
+	defer srv.Close()
+
+	e, err := NewExplorer("filscan", srv.URL, NewClient(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := e.MiningStats(context.Background(), "f01000", "1d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *res.LuckyValue != 0.85 || *res.BlocksMined != 2 || *res.WeightedBlocksMined != 3 || res.TotalRewards != "2000000000000000000" {
+		t.Fatalf("unexpected mining stats: %+v", res)
+	}
+}
+
+func TestExplorerFallback(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":{"miner_indicators":{"lucky_rate":"1.1"}}}`))
+	}))
+	defer up.Close()
+
+	client := NewClient(time.Minute)
+	filfox, _ := NewExplorer("filfox", down.URL, client)
+	filscan, _ := NewExplorer("filscan", up.URL, client)
+
+	f := &FilFox{
+		ctx:       context.Background(),
+		explorers: []Explorer{filfox, filscan},
+	}
+	res, explorer, err := f.miningStats("f01000", "30d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *res.LuckyValue != 1.1 || res.BlocksMined != nil || explorer != "filscan" {
+		t.Fatalf("unexpected mining stats: %+v from %s", res, explorer)
+	}
+}
+
+func TestFilFoxHasBlock(t *testing.T) {
+	c, err := cid.Decode("bafy2bzacecnamqgqmifpluoeldx7zzglxcljo6oja4vrmtj7432rphldpdmm2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/block/"+c.String() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	e, err := NewExplorer("filfox", srv.URL, NewClient(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := e.HasBlock(context.Background(), c)
+	if err != nil || !ok {
+		t.Fatalf("expected block found, got %v %v", ok, err)
+	}
+
+	other, _ := cid.Decode("bafy2bzaceaxm23epjsmh75yvzcecsrbavlmkcxnva66bkdebdcnyw3bjrc74u")
+	ok, err = e.HasBlock(context.Background(), other)
+	if err != nil || ok {
+		t.Fatalf("expected block not found, got %v %v", ok, err)
+	}
+}
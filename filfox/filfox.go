@@ -2,7 +2,7 @@ package filfox
 
 import (
 	"context"
-	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gh-efforts/lotus-monitor/config"
@@ -12,28 +12,56 @@ import (
 var log = logging.Logger("monitor/filfox")
 
 type FilFox struct {
-	ctx    context.Context
-	dc     *config.DynamicConfig
-	Client http.Client
+	ctx       context.Context
+	dc        *config.DynamicConfig
+	explorers []Explorer
+
+	queue   chan job
+	lk      sync.Mutex
+	pending map[string]struct{}
 }
 
-func NewFilFox(ctx context.Context, dc *config.DynamicConfig) *FilFox {
+func NewFilFox(ctx context.Context, dc *config.DynamicConfig) (*FilFox, error) {
+	client := NewClient(time.Duration(dc.ExplorerCache))
+
+	urls := map[string]string{
+		"filfox":  dc.FilFoxURL,
+		"filscan": dc.FilscanURL,
+	}
+	names := dc.Explorers
+	if len(names) == 0 {
+		names = []string{"filfox"}
+	}
+
+	var explorers []Explorer
+	for _, name := range names {
+		e, err := NewExplorer(name, urls[name], client)
+		if err != nil {
+			return nil, err
+		}
+		explorers = append(explorers, e)
+	}
+
 	f := &FilFox{
-		ctx:    ctx,
-		dc:     dc,
-		Client: http.Client{},
+		ctx:       ctx,
+		dc:        dc,
+		explorers: explorers,
+		queue:     make(chan job, queueSize),
+		pending:   make(map[string]struct{}),
 	}
-	return f
+	return f, nil
 }
 
 func (f *FilFox) Run() {
+	go f.worker()
 	go func() {
-		f.luckyValueRecords()
-		t := time.NewTicker(time.Duration(f.dc.RecordInterval.FilFox))
+		interval := time.Duration(f.dc.RecordInterval.FilFox)
+		f.schedule(interval)
+		t := time.NewTicker(interval)
 		for {
 			select {
 			case <-t.C:
-				f.luckyValueRecords()
+				f.schedule(interval)
 			case <-f.ctx.Done():
 				return
 			}
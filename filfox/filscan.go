@@ -0,0 +1,148 @@
+package filfox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/ipfs/go-cid"
+)
+
+// filscan 的统计窗口和 filfox 不同
+var filscanIntervals = map[string]string{
+	"1d":  "24h",
+	"7d":  "7d",
+	"30d": "1m",
+}
+
+type filscanExplorer struct {
+	url    string
+	client *Client
+}
+
+type filscanIndicators struct {
+	Result struct {
+		MinerIndicators struct {
+			PowerIncrease    json.Number `json:"power_increase"`
+			RawPowerIncrease json.Number `json:"raw_power_increase"`
+			BlockCount       json.Number `json:"block_count"`
+			WinCount         json.Number `json:"win_count"`
+			BlockReward      json.Number `json:"block_reward"`
+			LuckyRate        json.Number `json:"lucky_rate"`
+		} `json:"miner_indicators"`
+	} `json:"result"`
+	Error string `json:"error"`
+}
+
+func (e *filscanExplorer) Name() string {
+	return "filscan"
+}
+
+func (e *filscanExplorer) MiningStats(ctx context.Context, miner, duration string) (*MiningStats, error) {
+	interval, ok := filscanIntervals[duration]
+	if !ok {
+		return nil, fmt.Errorf("filscan: unsupported duration: %s", duration)
+	}
+
+	req, err := json.Marshal(map[string]interface{}{
+		"account_id": miner,
+		"filters":    map[string]string{"interval": interval},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := e.client.Post(ctx, e.url+"/IndicatorsByAccountID", req)
+	if err != nil {
+		return nil, err
+	}
+
+	var res filscanIndicators
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, err
+	}
+	if res.Error != "" {
+		return nil, errors.New(res.Error)
+	}
+
+	mi := res.Result.MinerIndicators
+	blocks, err := parseInt(mi.BlockCount)
+	if err != nil {
+		return nil, err
+	}
+	wins, err := parseInt(mi.WinCount)
+	if err != nil {
+		return nil, err
+	}
+	lucky, err := parseFloat(mi.LuckyRate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MiningStats{
+		RawBytePowerDelta:    mi.RawPowerIncrease.String(),
+		QualityAdjPowerDelta: mi.PowerIncrease.String(),
+		BlocksMined:          blocks,
+		WeightedBlocksMined:  wins,
+		TotalRewards:         mi.BlockReward.String(),
+		LuckyValue:           lucky,
+	}, nil
+}
+
+// parseInt filscan 没有返回的字段为 nil
+type filscanBlock struct {
+	Result struct {
+		BlockDetails *struct {
+			Cid string `json:"cid"`
+		} `json:"block_details"`
+	} `json:"result"`
+	Error string `json:"error"`
+}
+
+func (e *filscanExplorer) HasBlock(ctx context.Context, c cid.Cid) (bool, error) {
+	req, err := json.Marshal(map[string]string{"block_cid": c.String()})
+	if err != nil {
+		return false, err
+	}
+
+	body, err := e.client.Post(ctx, e.url+"/BlockDetails", req)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var res filscanBlock
+	if err := json.Unmarshal(body, &res); err != nil {
+		return false, err
+	}
+	if res.Error != "" {
+		return false, errors.New(res.Error)
+	}
+	return res.Result.BlockDetails != nil && res.Result.BlockDetails.Cid == c.String(), nil
+}
+
+func parseInt(n json.Number) (*int, error) {
+	if n == "" {
+		return nil, nil
+	}
+	v, err := strconv.Atoi(n.String())
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func parseFloat(n json.Number) (*float64, error) {
+	if n == "" {
+		return nil, nil
+	}
+	v, err := n.Float64()
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -1,11 +1,7 @@
 package filfox
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
-	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gh-efforts/lotus-monitor/metrics"
@@ -14,106 +10,44 @@ import (
 	"go.opencensus.io/tag"
 )
 
-var ErrTooManyRequests = errors.New("429 Too Many Requests")
-
-type rateLimit struct {
-	limit     int
-	remaining int
-	reset     int
-}
-type MiningStats struct {
-	RawBytePowerGrowth    string  `json:"rawBytePowerGrowth"`
-	QualityAdjPowerGrowth string  `json:"qualityAdjPowerGrowth"`
-	RawBytePowerDelta     string  `json:"rawBytePowerDelta"`
-	QualityAdjPowerDelta  string  `json:"qualityAdjPowerDelta"`
-	BlocksMined           int     `json:"blocksMined"`
-	WeightedBlocksMined   int     `json:"weightedBlocksMined"`
-	TotalRewards          string  `json:"totalRewards"`
-	NetworkTotalRewards   string  `json:"networkTotalRewards"`
-	EquivalentMiners      float64 `json:"equivalentMiners"`
-	RewardPerByte         float64 `json:"rewardPerByte"`
-	LuckyValue            float64 `json:"luckyValue"`
-	DurationPercentage    int     `json:"durationPercentage"`
-}
-
-func (f *FilFox) luckyValueRecords() {
-	stop := metrics.Timer(f.ctx, "filfox/luckyValueRecords")
+func (f *FilFox) luckyValueRecord(maddr, day string) error {
+	stop := metrics.Timer(f.ctx, "filfox/luckyValueRecord")
 	defer stop()
 
-	miners := f.dc.MinersList()
-
-	for _, maddr := range miners {
-		days := []string{"1d", "7d", "30d"}
-		for _, day := range days {
-			rl, err := f.luckyValueRecord(maddr.String(), day)
-			if err != nil {
-				log.Errorw("luckyValueRecord failed", "miner", maddr, "err", err)
-				metrics.RecordError(f.ctx, "filfox/luckyValueRecord")
-				if err == ErrTooManyRequests {
-					log.Warn("429 Too Many Requests, sleep one minute....")
-					time.Sleep(time.Minute)
-				}
-				continue
-			}
-			if rl.remaining < 3 {
-				log.Warnw("rate limit", "limit", rl.limit, "remaining", rl.remaining, "reset", rl.reset)
-				time.Sleep(time.Duration(rl.reset+1) * time.Second)
-			}
-		}
-	}
-}
-
-func (f *FilFox) luckyValueRecord(maddr, day string) (rateLimit, error) {
-	url := fmt.Sprintf("%s/address/%s/mining-stats?duration=%s", f.dc.FilFoxURL, maddr, day)
-	log.Debug(url)
-	resp, err := f.Client.Get(url)
-	if err != nil {
-		return rateLimit{}, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return rateLimit{}, ErrTooManyRequests
-		}
-		return rateLimit{}, errors.New(resp.Status)
-	}
-
-	var res MiningStats
-	err = json.NewDecoder(resp.Body).Decode(&res)
+	res, explorer, err := f.miningStats(maddr, day)
 	if err != nil {
-		return rateLimit{}, err
+		return err
 	}
 
+	//filfox 和 filscan 的统计口径不同，按来源区分
 	ctx, _ := tag.New(f.ctx,
 		tag.Upsert(metrics.MinerID, maddr),
 		tag.Upsert(metrics.LuckyValueDay, day),
+		tag.Upsert(metrics.Explorer, explorer),
 	)
-	stats.Record(ctx, metrics.LuckyValue.M(res.LuckyValue))
+	recordMiningStats(ctx, res)
 
-	return parseRateLimit(resp.Header)
+	ctx, _ = tag.New(f.ctx,
+		tag.Upsert(metrics.MinerID, maddr),
+		tag.Upsert(metrics.Explorer, explorer),
+	)
+	stats.Record(ctx, metrics.ExplorerLastSuccess.M(time.Now().Unix()))
+
+	return nil
 }
 
-func parseRateLimit(header http.Header) (rateLimit, error) {
-	log.Debug(header)
-	limit, err := strconv.Atoi(header.Get("x-ratelimit-limit"))
-	if err != nil {
-		return rateLimit{}, err
-	}
-	remaining, err := strconv.Atoi(header.Get("x-ratelimit-remaining"))
-	if err != nil {
-		return rateLimit{}, err
-	}
-	reset, err := strconv.Atoi(header.Get("x-ratelimit-reset"))
-	if err != nil {
-		return rateLimit{}, err
-	}
+// miningStats 按配置顺序依次尝试 explorer，前一个失败时使用下一个
+func (f *FilFox) miningStats(maddr, day string) (*MiningStats, string, error) {
+	var errs []error
+	for _, e := range f.explorers {
+		res, err := e.MiningStats(f.ctx, maddr, day)
+		if err == nil {
+			return res, e.Name(), nil
+		}
 
-	r := rateLimit{
-		limit:     limit,
-		remaining: remaining,
-		reset:     reset,
+		log.Warnw("explorer failed", "explorer", e.Name(), "miner", maddr, "day", day, "err", err)
+		errs = append(errs, err)
 	}
-	log.Debug(r)
-	return r, nil
+
+	return nil, "", errors.Join(errs...)
 }
@@ -0,0 +1,84 @@
+package filfox
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"go.opencensus.io/stats"
+)
+
+// recordMiningStats 导出 MiningStats 里 explorer 提供了的数值字段，字符串字段是 attoFIL 或 bytes
+func recordMiningStats(ctx context.Context, res *MiningStats) {
+	ms := []stats.Measurement{metrics.MiningFetchTimestamp.M(time.Now().Unix())}
+
+	floats := map[*stats.Float64Measure]*float64{
+		metrics.LuckyValue:             res.LuckyValue,
+		metrics.MiningEquivalentMiners: res.EquivalentMiners,
+		metrics.MiningRewardPerByte:    res.RewardPerByte,
+	}
+	for m, v := range floats {
+		if v != nil {
+			ms = append(ms, m.M(*v))
+		}
+	}
+
+	ints := map[*stats.Int64Measure]*int{
+		metrics.MiningBlocksMined:         res.BlocksMined,
+		metrics.MiningWeightedBlocksMined: res.WeightedBlocksMined,
+		metrics.MiningDurationPercentage:  res.DurationPercentage,
+	}
+	for m, v := range ints {
+		if v != nil {
+			ms = append(ms, m.M(int64(*v)))
+		}
+	}
+	stats.Record(ctx, ms...)
+
+	fil := map[*stats.Float64Measure]string{
+		metrics.MiningTotalRewards:        res.TotalRewards,
+		metrics.MiningNetworkTotalRewards: res.NetworkTotalRewards,
+	}
+	for m, v := range fil {
+		if f, ok := parseAttoFIL(v); ok {
+			stats.Record(ctx, m.M(f))
+		}
+	}
+
+	bytes := map[*stats.Float64Measure]string{
+		metrics.MiningRawBytePowerGrowth:    res.RawBytePowerGrowth,
+		metrics.MiningQualityAdjPowerGrowth: res.QualityAdjPowerGrowth,
+		metrics.MiningRawBytePowerDelta:     res.RawBytePowerDelta,
+		metrics.MiningQualityAdjPowerDelta:  res.QualityAdjPowerDelta,
+	}
+	for m, v := range bytes {
+		if f, ok := parseBytes(v); ok {
+			stats.Record(ctx, m.M(f))
+		}
+	}
+}
+
+func parseAttoFIL(s string) (float64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	v, err := types.BigFromString(s)
+	if err != nil {
+		log.Warnw("parse attoFIL failed", "value", s, "err", err)
+		return 0, false
+	}
+	return types.BigDivFloat(v, types.FromFil(1)), true
+}
+
+func parseBytes(s string) (float64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	v, err := types.BigFromString(s)
+	if err != nil {
+		log.Warnw("parse bytes failed", "value", s, "err", err)
+		return 0, false
+	}
+	return types.BigDivFloat(v, types.NewInt(1)), true
+}
@@ -0,0 +1,112 @@
+package filfox
+
+import (
+	"time"
+
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"go.opencensus.io/stats"
+)
+
+var days = []string{"1d", "7d", "30d"}
+
+const (
+	maxRetries   = 3
+	retryBackoff = time.Minute
+	queueSize    = 1024
+)
+
+type job struct {
+	miner   string
+	day     string
+	attempt int
+}
+
+func (j job) key() string {
+	return j.miner + "/" + j.day
+}
+
+// schedule 把一轮 miner × day 的请求均匀分散到 interval 内放入队列
+func (f *FilFox) schedule(interval time.Duration) {
+	var jobs []job
+	for _, maddr := range f.dc.MinersList() {
+		for _, day := range days {
+			jobs = append(jobs, job{miner: maddr.String(), day: day})
+		}
+	}
+	if len(jobs) == 0 {
+		return
+	}
+
+	spacing := interval / time.Duration(len(jobs))
+	log.Debugw("schedule lucky value jobs", "jobs", len(jobs), "spacing", spacing)
+
+	for i, j := range jobs {
+		if i > 0 {
+			select {
+			case <-time.After(spacing):
+			case <-f.ctx.Done():
+				return
+			}
+		}
+		f.enqueue(j)
+	}
+}
+
+// enqueue 同一个 miner/day 已经在队列里时跳过
+func (f *FilFox) enqueue(j job) {
+	f.lk.Lock()
+	if _, ok := f.pending[j.key()]; ok {
+		f.lk.Unlock()
+		log.Debugw("job already queued", "miner", j.miner, "day", j.day)
+		return
+	}
+	f.pending[j.key()] = struct{}{}
+	f.lk.Unlock()
+
+	select {
+	case f.queue <- j:
+	default:
+		f.lk.Lock()
+		delete(f.pending, j.key())
+		f.lk.Unlock()
+		log.Warnw("lucky value queue full, drop job", "miner", j.miner, "day", j.day)
+		metrics.RecordError(f.ctx, "filfox/queueFull")
+	}
+	stats.Record(f.ctx, metrics.ExplorerQueueDepth.M(int64(len(f.queue))))
+}
+
+func (f *FilFox) worker() {
+	for {
+		select {
+		case j := <-f.queue:
+			stats.Record(f.ctx, metrics.ExplorerQueueDepth.M(int64(len(f.queue))))
+			f.process(j)
+		case <-f.ctx.Done():
+			return
+		}
+	}
+}
+
+// process 失败时按指数退避重试
+func (f *FilFox) process(j job) {
+	f.lk.Lock()
+	delete(f.pending, j.key())
+	f.lk.Unlock()
+
+	err := f.luckyValueRecord(j.miner, j.day)
+	if err == nil {
+		log.Debugw("luckyValueRecord success", "miner", j.miner, "day", j.day)
+		return
+	}
+
+	log.Errorw("luckyValueRecord failed", "miner", j.miner, "day", j.day, "attempt", j.attempt, "err", err)
+	metrics.RecordError(f.ctx, "filfox/luckyValueRecord")
+
+	if j.attempt >= maxRetries {
+		return
+	}
+	backoff := retryBackoff << j.attempt
+	time.AfterFunc(backoff, func() {
+		f.enqueue(job{miner: j.miner, day: j.day, attempt: j.attempt + 1})
+	})
+}
@@ -0,0 +1,109 @@
+package filfox
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket 按 host 限流：
+// 没有响应头时按 defaultRate 匀速补充；
+// 收到 x-ratelimit-* 后和服务端保持一致，remaining 用完等到 reset 再补满到 limit
+type tokenBucket struct {
+	lk       sync.Mutex
+	capacity float64
+	tokens   float64
+	rate     float64 //每秒补充的 token 数
+	last     time.Time
+	resetAt  time.Time
+}
+
+const defaultRate = 1.0
+
+func newTokenBucket() *tokenBucket {
+	return &tokenBucket{
+		capacity: 1,
+		tokens:   1,
+		rate:     defaultRate,
+		last:     time.Now(),
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if !b.resetAt.IsZero() {
+		if now.Before(b.resetAt) {
+			b.last = now
+			return
+		}
+		b.tokens = b.capacity
+		b.resetAt = time.Time{}
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+}
+
+// reserve 取一个 token，返回需要等待的时间，0 表示已经取到
+func (b *tokenBucket) reserve() time.Duration {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	now := time.Now()
+	b.refill(now)
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	if !b.resetAt.IsZero() {
+		return b.resetAt.Sub(now)
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	for {
+		d := b.reserve()
+		if d <= 0 {
+			return nil
+		}
+
+		log.Debugw("rate limit, waiting", "duration", d)
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// update 根据响应头同步服务端的限流状态
+func (b *tokenBucket) update(rl rateLimit) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	now := time.Now()
+	if rl.limit > 0 {
+		b.capacity = float64(rl.limit)
+	}
+	b.tokens = float64(rl.remaining)
+	b.last = now
+	if rl.remaining <= 0 {
+		b.resetAt = now.Add(time.Duration(rl.reset+1) * time.Second)
+	} else if rl.reset > 0 {
+		//剩余次数在 reset 之前均匀使用
+		b.rate = float64(rl.remaining) / float64(rl.reset)
+	}
+}
+
+// block 收到 429 时，d 时间内不再请求
+func (b *tokenBucket) block(d time.Duration) {
+	b.lk.Lock()
+	defer b.lk.Unlock()
+
+	b.tokens = 0
+	b.resetAt = time.Now().Add(d)
+}
@@ -0,0 +1,105 @@
+package fullnode
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+func (n *FullNode) beneficiaryRecords(tsk types.TipSetKey) error {
+	stop := metrics.Timer(n.ctx, "fullnode/beneficiaryRecords")
+	defer stop()
+
+	miners := n.dc.MinersList()
+
+	var failed atomic.Int64
+	wg := sync.WaitGroup{}
+	wg.Add(len(miners))
+
+	for _, maddr := range miners {
+		go func(maddr address.Address) {
+			defer wg.Done()
+			if err := n.beneficiaryRecord(maddr, tsk); err != nil {
+				log.Errorw("beneficiaryRecord failed", "miner", maddr, "err", err)
+				metrics.RecordError(n.ctx, "fullnode/beneficiaryRecord")
+				failed.Add(1)
+			} else {
+				log.Debugw("beneficiaryRecord success", "miner", maddr)
+			}
+		}(maddr)
+	}
+	wg.Wait()
+
+	if f := failed.Load(); f > 0 {
+		return fmt.Errorf("beneficiaryRecord failed for %d/%d miners", f, len(miners))
+	}
+	return nil
+}
+
+func (n *FullNode) beneficiaryRecord(maddr address.Address, tsk types.TipSetKey) error {
+	ctx, _ := tag.New(n.ctx,
+		tag.Upsert(metrics.MinerID, maddr.String()),
+	)
+	mi, err := n.dc.Cache.MinerInfo(ctx, maddr, tsk)
+	if err != nil {
+		return err
+	}
+
+	//beneficiary 是 owner 时没有额度限制，只导出 has_term=0，和采集失败区分开
+	bctx, _ := tag.New(ctx,
+		tag.Upsert(metrics.ActorAddress, mi.Beneficiary.String()),
+	)
+	if mi.BeneficiaryTerm != nil && mi.Beneficiary != mi.Owner {
+		term := mi.BeneficiaryTerm
+		remaining := big.Sub(term.Quota, term.UsedQuota)
+		if remaining.LessThan(big.Zero()) {
+			remaining = big.Zero()
+		}
+
+		stats.Record(bctx, metrics.BeneficiaryHasTerm.M(1))
+		stats.Record(bctx, metrics.BeneficiaryQuota.M(types.BigDivFloat(term.Quota, types.FromFil(1))))
+		stats.Record(bctx, metrics.BeneficiaryUsedQuota.M(types.BigDivFloat(term.UsedQuota, types.FromFil(1))))
+		stats.Record(bctx, metrics.BeneficiaryRemainingQuota.M(types.BigDivFloat(remaining, types.FromFil(1))))
+		stats.Record(bctx, metrics.BeneficiaryExpiration.M(int64(term.Expiration)))
+	} else {
+		stats.Record(bctx, metrics.BeneficiaryHasTerm.M(0))
+	}
+
+	pending := mi.PendingBeneficiaryTerm
+	approved := map[string]bool{
+		"beneficiary": pending != nil && pending.ApprovedByBeneficiary,
+		"nominee":     pending != nil && pending.ApprovedByNominee,
+	}
+	for k, v := range approved {
+		ctx, _ := tag.New(ctx,
+			tag.Upsert(metrics.ApprovedBy, k),
+		)
+		var a int64
+		if v {
+			a = 1
+		}
+		stats.Record(ctx, metrics.BeneficiaryPendingApproved.M(a))
+	}
+
+	if pending == nil {
+		stats.Record(ctx, metrics.BeneficiaryPending.M(0))
+		return nil
+	}
+	stats.Record(ctx, metrics.BeneficiaryPending.M(1))
+
+	ctx, _ = tag.New(ctx,
+		tag.Upsert(metrics.ActorAddress, pending.NewBeneficiary.String()),
+	)
+	stats.Record(ctx, metrics.BeneficiaryPendingQuota.M(types.BigDivFloat(pending.NewQuota, types.FromFil(1))))
+	stats.Record(ctx, metrics.BeneficiaryPendingExpiration.M(int64(pending.NewExpiration)))
+
+	log.Debugw("beneficiaryRecord", "miner", maddr, "newBeneficiary", pending.NewBeneficiary, "approvedByBeneficiary", pending.ApprovedByBeneficiary, "approvedByNominee", pending.ApprovedByNominee)
+	return nil
+}
@@ -1,8 +1,10 @@
 package fullnode
 
 import (
+	"fmt"
 	"strconv"
 	"sync"
+	"sync/atomic"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/lotus/chain/types"
@@ -11,41 +13,48 @@ import (
 	"go.opencensus.io/tag"
 )
 
-func (n *FullNode) deadlineRecords() {
+func (n *FullNode) deadlineRecords(tsk types.TipSetKey) error {
 	stop := metrics.Timer(n.ctx, "fullnode/deadlineRecords")
 	defer stop()
 
 	miners := n.dc.MinersList()
 
+	var failed atomic.Int64
 	wg := sync.WaitGroup{}
 	wg.Add(len(miners))
 
 	for _, maddr := range miners {
 		go func(maddr address.Address) {
 			defer wg.Done()
-			if err := n.deadlineRecord(maddr); err != nil {
+			if err := n.deadlineRecord(maddr, tsk); err != nil {
 				log.Errorw("deadlineRecord failed", "miner", maddr, "err", err)
 				metrics.RecordError(n.ctx, "fullnode/deadlineRecord")
+				failed.Add(1)
 			} else {
 				log.Debugw("deadlineRecord success", "miner", maddr)
 			}
 		}(maddr)
 	}
 	wg.Wait()
+
+	if f := failed.Load(); f > 0 {
+		return fmt.Errorf("deadlineRecord failed for %d/%d miners", f, len(miners))
+	}
+	return nil
 }
 
-func (n *FullNode) deadlineRecord(maddr address.Address) error {
+func (n *FullNode) deadlineRecord(maddr address.Address, tsk types.TipSetKey) error {
 	ctx, _ := tag.New(n.ctx,
 		tag.Upsert(metrics.MinerID, maddr.String()),
 	)
 	api := n.dc.LotusApi
 
-	di, err := api.StateMinerProvingDeadline(ctx, maddr, types.EmptyTSK)
+	di, err := api.StateMinerProvingDeadline(ctx, maddr, tsk)
 	if err != nil {
 		return err
 	}
 
-	deadlines, err := api.StateMinerDeadlines(ctx, maddr, types.EmptyTSK)
+	deadlines, err := api.StateMinerDeadlines(ctx, maddr, tsk)
 	if err != nil {
 		return err
 	}
@@ -57,7 +66,7 @@ func (n *FullNode) deadlineRecord(maddr address.Address) error {
 		return err
 	}
 
-	partitions, err := api.StateMinerPartitions(ctx, maddr, uint64(dlIdx), types.EmptyTSK)
+	partitions, err := api.StateMinerPartitions(ctx, maddr, uint64(dlIdx), tsk)
 	if err != nil {
 		return err
 	}
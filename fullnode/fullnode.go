@@ -2,8 +2,10 @@ package fullnode
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/gh-efforts/lotus-monitor/config"
 	logging "github.com/ipfs/go-log/v2"
 )
@@ -30,11 +32,25 @@ func (n *FullNode) Run() {
 		for {
 			select {
 			case <-t.C:
-				n.deadlineRecords()
-				n.minerRecords()
+				n.deadlineRecords(types.EmptyTSK)
+				n.minerRecords(types.EmptyTSK)
+				n.beneficiaryRecords(types.EmptyTSK)
 			case <-n.ctx.Done():
 				return
 			}
 		}
 	}()
 }
+
+func (n *FullNode) Name() string {
+	return "fullnode"
+}
+
+// Record 事件驱动模式下由 notify 调用，所有请求固定在 ts；有 miner 失败时返回错误，notify 不记录采集高度
+func (n *FullNode) Record(ctx context.Context, ts *types.TipSet) error {
+	return errors.Join(
+		n.deadlineRecords(ts.Key()),
+		n.minerRecords(ts.Key()),
+		n.beneficiaryRecords(ts.Key()),
+	)
+}
@@ -1,7 +1,9 @@
 package fullnode
 
 import (
+	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/lotus/chain/types"
@@ -10,37 +12,44 @@ import (
 	"go.opencensus.io/tag"
 )
 
-func (n *FullNode) minerRecords() {
+func (n *FullNode) minerRecords(tsk types.TipSetKey) error {
 	stop := metrics.Timer(n.ctx, "fullnode/minerRecords")
 	defer stop()
 
 	miners := n.dc.MinersList()
 	log.Debug(miners)
 
+	var failed atomic.Int64
 	wg := sync.WaitGroup{}
 	wg.Add(len(miners))
 
 	for _, maddr := range miners {
 		go func(maddr address.Address) {
 			defer wg.Done()
-			if err := n.minerRecord(maddr); err != nil {
+			if err := n.minerRecord(maddr, tsk); err != nil {
 				log.Errorw("minerRecord failed", "miner", maddr, "err", err)
 				metrics.RecordError(n.ctx, "fullnode/minerRecord")
+				failed.Add(1)
 			} else {
 				log.Debugw("minerRecord success", "miner", maddr)
 			}
 		}(maddr)
 	}
 	wg.Wait()
+
+	if f := failed.Load(); f > 0 {
+		return fmt.Errorf("minerRecord failed for %d/%d miners", f, len(miners))
+	}
+	return nil
 }
 
-func (n *FullNode) minerRecord(maddr address.Address) error {
+func (n *FullNode) minerRecord(maddr address.Address, tsk types.TipSetKey) error {
 	ctx, _ := tag.New(n.ctx,
 		tag.Upsert(metrics.MinerID, maddr.String()),
 	)
 	api := n.dc.LotusApi
 
-	ms, err := api.StateMinerSectorCount(ctx, maddr, types.EmptyTSK)
+	ms, err := api.StateMinerSectorCount(ctx, maddr, tsk)
 	if err != nil {
 		return err
 	}
@@ -48,14 +57,14 @@ func (n *FullNode) minerRecord(maddr address.Address) error {
 	stats.Record(ctx, metrics.MinerActives.M(int64(ms.Active)))
 	stats.Record(ctx, metrics.MinerLives.M(int64(ms.Live)))
 
-	mp, err := api.StateMinerPower(ctx, maddr, types.EmptyTSK)
+	mp, err := api.StateMinerPower(ctx, maddr, tsk)
 	if err != nil {
 		return err
 	}
 	stats.Record(ctx, metrics.MinerRawBytePower.M(mp.MinerPower.RawBytePower.Int64()))
 	stats.Record(ctx, metrics.MinerQualityAdjPower.M(mp.MinerPower.QualityAdjPower.Int64()))
 
-	mi, err := api.StateMinerInfo(ctx, maddr, types.EmptyTSK)
+	mi, err := n.dc.Cache.MinerInfo(ctx, maddr, tsk)
 	if err != nil {
 		return err
 	}
@@ -72,7 +81,7 @@ func (n *FullNode) minerRecord(maddr address.Address) error {
 			tag.Upsert(metrics.ActorAddress, k.String()),
 			tag.Upsert(metrics.AddressType, v),
 		)
-		actor, err := api.StateGetActor(ctx, k, types.EmptyTSK)
+		actor, err := api.StateGetActor(ctx, k, tsk)
 		if err != nil {
 			return err
 		}
@@ -80,7 +89,7 @@ func (n *FullNode) minerRecord(maddr address.Address) error {
 		stats.Record(ctx, metrics.Balance.M(types.BigDivFloat(actor.Balance, types.FromFil(1))))
 	}
 
-	balance, err := api.StateMinerAvailableBalance(ctx, maddr, types.EmptyTSK)
+	balance, err := api.StateMinerAvailableBalance(ctx, maddr, tsk)
 	if err != nil {
 		return err
 	}
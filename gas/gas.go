@@ -0,0 +1,87 @@
+// 按 lotus 的规则拆分消息执行后的 gas 费用，
+// 避免引入 lotus chain/vm（依赖 filecoin-ffi）
+
+package gas
+
+import (
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+const (
+	gasOveruseNum   = 11
+	gasOveruseDenom = 10
+)
+
+type Outputs struct {
+	BaseFeeBurn        abi.TokenAmount `json:"baseFeeBurn"`
+	OverEstimationBurn abi.TokenAmount `json:"overEstimationBurn"`
+	MinerPenalty       abi.TokenAmount `json:"minerPenalty"`
+	MinerTip           abi.TokenAmount `json:"minerTip"`
+	Refund             abi.TokenAmount `json:"refund"`
+
+	GasRefund int64 `json:"gasRefund"`
+	GasBurned int64 `json:"gasBurned"`
+}
+
+// Total 发送方实际支付的费用
+func (o Outputs) Total() abi.TokenAmount {
+	return big.Sum(o.BaseFeeBurn, o.OverEstimationBurn, o.MinerTip)
+}
+
+func overestimationBurn(gasUsed, gasLimit int64) (int64, int64) {
+	if gasUsed == 0 {
+		return 0, gasLimit
+	}
+
+	over := gasLimit - (gasOveruseNum*gasUsed)/gasOveruseDenom
+	if over < 0 {
+		return gasLimit - gasUsed, 0
+	}
+	if over > gasUsed {
+		over = gasUsed
+	}
+
+	gasToBurn := big.NewInt(gasLimit - gasUsed)
+	gasToBurn = big.Mul(gasToBurn, big.NewInt(over))
+	gasToBurn = big.Div(gasToBurn, big.NewInt(gasUsed))
+
+	return gasLimit - gasUsed - gasToBurn.Int64(), gasToBurn.Int64()
+}
+
+// ComputeOutputs 和 lotus vm.ComputeGasOutputs 一致（chargeNetworkFee 为 true）
+func ComputeOutputs(gasUsed, gasLimit int64, baseFee, feeCap, gasPremium abi.TokenAmount) Outputs {
+	gasUsedBig := big.NewInt(gasUsed)
+	out := Outputs{
+		BaseFeeBurn:        big.Zero(),
+		OverEstimationBurn: big.Zero(),
+		MinerPenalty:       big.Zero(),
+		MinerTip:           big.Zero(),
+		Refund:             big.Zero(),
+	}
+
+	baseFeeToPay := baseFee
+	if baseFee.GreaterThan(feeCap) {
+		baseFeeToPay = feeCap
+		out.MinerPenalty = big.Mul(big.Sub(baseFee, feeCap), gasUsedBig)
+	}
+	out.BaseFeeBurn = big.Mul(baseFeeToPay, gasUsedBig)
+
+	minerTip := gasPremium
+	if big.Add(baseFeeToPay, minerTip).GreaterThan(feeCap) {
+		minerTip = big.Sub(feeCap, baseFeeToPay)
+	}
+	out.MinerTip = big.Mul(minerTip, big.NewInt(gasLimit))
+
+	out.GasRefund, out.GasBurned = overestimationBurn(gasUsed, gasLimit)
+	if out.GasBurned != 0 {
+		gasBurnedBig := big.NewInt(out.GasBurned)
+		out.OverEstimationBurn = big.Mul(baseFeeToPay, gasBurnedBig)
+		out.MinerPenalty = big.Add(out.MinerPenalty, big.Mul(big.Sub(baseFee, baseFeeToPay), gasBurnedBig))
+	}
+
+	required := big.Mul(big.NewInt(gasLimit), feeCap)
+	out.Refund = big.Sub(required, out.Total())
+
+	return out
+}
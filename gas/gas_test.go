@@ -0,0 +1,34 @@
+package gas
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+func TestComputeOutputs(t *testing.T) {
+	out := ComputeOutputs(100, 200, big.NewInt(10), big.NewInt(20), big.NewInt(5))
+
+	// over = 200 - 110 = 90, burned = (200-100)*90/100 = 90
+	if out.GasBurned != 90 || out.GasRefund != 10 {
+		t.Fatalf("unexpected gas burned: %d refund: %d", out.GasBurned, out.GasRefund)
+	}
+	if !out.BaseFeeBurn.Equals(big.NewInt(1000)) {
+		t.Fatalf("unexpected base fee burn: %s", out.BaseFeeBurn)
+	}
+	if !out.OverEstimationBurn.Equals(big.NewInt(900)) {
+		t.Fatalf("unexpected overestimation burn: %s", out.OverEstimationBurn)
+	}
+	if !out.MinerTip.Equals(big.NewInt(1000)) {
+		t.Fatalf("unexpected miner tip: %s", out.MinerTip)
+	}
+	if !out.Refund.Equals(big.NewInt(4000 - 2900)) {
+		t.Fatalf("unexpected refund: %s", out.Refund)
+	}
+
+	// base fee 高于 fee cap 时，矿工被罚款，tip 为 0
+	out = ComputeOutputs(100, 100, big.NewInt(30), big.NewInt(20), big.NewInt(5))
+	if !out.MinerTip.IsZero() || !out.MinerPenalty.Equals(big.NewInt(1000)) {
+		t.Fatalf("unexpected tip: %s penalty: %s", out.MinerTip, out.MinerPenalty)
+	}
+}
@@ -0,0 +1,125 @@
+package messages
+
+import (
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/gas"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/gh-efforts/lotus-monitor/notify"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+// watchChain 落后 head OrphanCheckHeight 个高度索引，避开链重组
+func (m *Messages) watchChain() {
+	notify.Watch(m.ctx, m.dc.LotusApi, "messages", func(changes []*api.HeadChange) {
+		head := notify.Head(changes)
+		if head == nil {
+			return
+		}
+
+		if err := m.index(head); err != nil {
+			log.Errorw("index failed", "height", head.Height(), "err", err)
+			metrics.RecordError(m.ctx, "messages/index")
+		}
+	})
+}
+
+// index 处理上次索引之后到 head-OrphanCheckHeight 之间的 tipset，最多回溯一个 finality
+func (m *Messages) index(head *types.TipSet) error {
+	stop := metrics.Timer(m.ctx, "messages/index")
+	defer stop()
+
+	target, err := notify.Behind(m.ctx, m.dc.LotusApi, head, abi.ChainEpoch(m.dc.OrphanCheckHeight))
+	if err != nil {
+		return err
+	}
+
+	last := m.lastHeight()
+	if last == 0 {
+		last = target.Height() - 1
+	} else if gap := target.Height() - last; gap > policy.ChainFinality {
+		log.Warnw("index gap exceeds finality, skip", "last", last, "target", target.Height(), "skipped", gap-1)
+		stats.Record(m.ctx, metrics.MessageIndexSkipped.M(int64(gap-1)))
+		last = target.Height() - 1
+	}
+
+	var tss []*types.TipSet
+	for ts := target; ts.Height() > last; {
+		tss = append(tss, ts)
+
+		parent, err := m.dc.LotusApi.ChainGetTipSet(m.ctx, ts.Parents())
+		if err != nil {
+			return err
+		}
+		ts = parent
+	}
+
+	if err := m.refreshSenders(); err != nil {
+		return err
+	}
+
+	for i := len(tss) - 1; i >= 0; i-- {
+		if err := m.processTipSet(tss[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Messages) processTipSet(ts *types.TipSet) error {
+	msgs, rcpts, err := notify.ParentMessages(m.ctx, m.dc.LotusApi, ts)
+	if err != nil {
+		return err
+	}
+
+	baseFee := ts.Blocks()[0].ParentBaseFee
+	date := time.Unix(int64(ts.MinTimestamp()), 0).UTC().Format(dateLayout)
+	daily := map[string]*DailyCost{}
+	allow := m.allowMethods()
+	//commit 成功后再导出，失败重试时不会重复计数
+	var records []func()
+
+	for i, pm := range msgs {
+		msg := pm.Message
+		s, ok := m.sender(msg)
+		if !ok {
+			continue
+		}
+
+		rcpt := rcpts[i]
+		method := m.methodName(msg.To, msg.Method, ts.Parents())
+		out := gas.ComputeOutputs(rcpt.GasUsed, msg.GasLimit, baseFee, msg.GasFeeCap, msg.GasPremium)
+
+		ctx, _ := tag.New(m.ctx,
+			tag.Upsert(metrics.MinerID, s.miner.String()),
+			tag.Upsert(metrics.Method, method),
+			tag.Upsert(metrics.AddressType, s.typ),
+		)
+		if err := m.addDaily(daily, date, s.miner.String(), method, out); err != nil {
+			return err
+		}
+
+		mc := pm.Cid
+		records = append(records, func() {
+			recordCost(ctx, out)
+			if rcpt.ExitCode.IsError() {
+				m.recordFailure(ctx, mc, rcpt.ExitCode)
+			}
+			m.securityCheck(mc, msg, method, allow)
+		})
+	}
+
+	if err := m.commit(ts.Height(), daily); err != nil {
+		return err
+	}
+	for _, r := range records {
+		r()
+	}
+	return nil
+}
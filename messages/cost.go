@@ -0,0 +1,159 @@
+package messages
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/gas"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"go.opencensus.io/stats"
+)
+
+const dateLayout = "2006-01-02"
+
+var dailyPrefix = datastore.NewKey("/daily")
+
+// DailyCost 每个 miner 每个方法每天（UTC）的 gas 花费
+type DailyCost struct {
+	Date               string          `json:"date"`
+	Miner              string          `json:"miner"`
+	Method             string          `json:"method"`
+	Count              int64           `json:"count"`
+	BaseFeeBurn        abi.TokenAmount `json:"baseFeeBurn"`
+	OverEstimationBurn abi.TokenAmount `json:"overEstimationBurn"`
+	MinerTip           abi.TokenAmount `json:"minerTip"`
+	Total              abi.TokenAmount `json:"total"`
+}
+
+func dailyKey(date, miner, method string) datastore.Key {
+	return dailyPrefix.ChildString(date).ChildString(miner).ChildString(method)
+}
+
+func filFloat(v abi.TokenAmount) float64 {
+	return types.BigDivFloat(v, types.FromFil(1))
+}
+
+func recordCost(ctx context.Context, out gas.Outputs) {
+	stats.Record(ctx,
+		metrics.MessageCount.M(1),
+		metrics.MessageBaseFeeBurn.M(filFloat(out.BaseFeeBurn)),
+		metrics.MessageOverEstimationBurn.M(filFloat(out.OverEstimationBurn)),
+		metrics.MessageMinerTip.M(filFloat(out.MinerTip)),
+	)
+}
+
+// addDaily 累加到每日统计，commit 时写入
+func (m *Messages) addDaily(daily map[string]*DailyCost, date, miner, method string, out gas.Outputs) error {
+	key := dailyKey(date, miner, method)
+	d, ok := daily[key.String()]
+	if !ok {
+		var err error
+		d, err = m.getDaily(key)
+		if errors.Is(err, datastore.ErrNotFound) {
+			d = &DailyCost{
+				Date:               date,
+				Miner:              miner,
+				Method:             method,
+				BaseFeeBurn:        big.Zero(),
+				OverEstimationBurn: big.Zero(),
+				MinerTip:           big.Zero(),
+				Total:              big.Zero(),
+			}
+		} else if err != nil {
+			return err
+		}
+		daily[key.String()] = d
+	}
+
+	d.Count += 1
+	d.BaseFeeBurn = big.Add(d.BaseFeeBurn, out.BaseFeeBurn)
+	d.OverEstimationBurn = big.Add(d.OverEstimationBurn, out.OverEstimationBurn)
+	d.MinerTip = big.Add(d.MinerTip, out.MinerTip)
+	d.Total = big.Add(d.Total, out.Total())
+
+	return nil
+}
+
+func (m *Messages) getDaily(key datastore.Key) (*DailyCost, error) {
+	data, err := m.ds.Get(m.ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var d DailyCost
+	err = json.Unmarshal(data, &d)
+	return &d, err
+}
+
+// commit 每日统计和处理到的高度一起写入，重启后不会重复统计
+func (m *Messages) commit(h abi.ChainEpoch, daily map[string]*DailyCost) error {
+	batch, err := m.ds.Batch(m.ctx)
+	if err != nil {
+		return err
+	}
+
+	for k, d := range daily {
+		data, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(m.ctx, datastore.NewKey(k), data); err != nil {
+			return err
+		}
+	}
+	if err := batch.Put(m.ctx, lastHeightKey, []byte(strconv.FormatInt(int64(h), 10))); err != nil {
+		return err
+	}
+	if err := batch.Commit(m.ctx); err != nil {
+		return err
+	}
+
+	m.lk.Lock()
+	m.last = h
+	m.lk.Unlock()
+
+	return nil
+}
+
+// Daily 返回某一天的 gas 花费，miner 为空时返回所有 miner
+func (m *Messages) Daily(date, miner string) ([]DailyCost, error) {
+	prefix := dailyPrefix.ChildString(date)
+	if miner != "" {
+		prefix = prefix.ChildString(miner)
+	}
+
+	res, err := m.ds.Query(m.ctx, query.Query{Prefix: prefix.String()})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+
+	ret := []DailyCost{}
+	for e := range res.Next() {
+		if e.Error != nil {
+			return nil, e.Error
+		}
+		var d DailyCost
+		if err := json.Unmarshal(e.Value, &d); err != nil {
+			return nil, err
+		}
+		ret = append(ret, d)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Miner != ret[j].Miner {
+			return ret[i].Miner < ret[j].Miner
+		}
+		return ret[i].Method < ret[j].Method
+	})
+
+	return ret, nil
+}
@@ -0,0 +1,56 @@
+package messages
+
+import (
+	"context"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/gh-efforts/lotus-monitor/gas"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+)
+
+func TestDailyCost(t *testing.T) {
+	ctx := context.Background()
+	m := &Messages{
+		ctx: ctx,
+		ds:  dssync.MutexWrap(datastore.NewMapDatastore()),
+	}
+
+	out := gas.ComputeOutputs(100, 200, big.NewInt(10), big.NewInt(20), big.NewInt(5))
+
+	//两个 tipset 分别提交，第二次在已保存的统计上累加
+	for h := abi.ChainEpoch(100); h < 102; h++ {
+		daily := map[string]*DailyCost{}
+		if err := m.addDaily(daily, "2024-01-01", "f01000", "SubmitWindowedPoSt", out); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.addDaily(daily, "2024-01-01", "f010001", "PreCommitSector", out); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.commit(h, daily); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	costs, err := m.Daily("2024-01-01", "f01000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(costs) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(costs))
+	}
+	c := costs[0]
+	if c.Count != 2 || !c.Total.Equals(big.Mul(out.Total(), big.NewInt(2))) {
+		t.Fatalf("unexpected cost: %+v", c)
+	}
+
+	all, err := m.Daily("2024-01-01", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(all))
+	}
+}
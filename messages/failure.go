@@ -0,0 +1,62 @@
+package messages
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/exitcode"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/ipfs/go-cid"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+const maxErrorTag = 128
+
+// recordFailure 上链消息执行失败：按 miner/方法/exit code 计数，
+// 并发出带消息 cid 和错误信息的事件，OrphanReset 之后归零
+func (m *Messages) recordFailure(ctx context.Context, mc cid.Cid, code exitcode.ExitCode) {
+	errMsg := m.replayError(mc)
+	log.Warnw("message failed", "cid", mc, "exitCode", code, "err", errMsg)
+
+	ctx, _ = tag.New(ctx,
+		tag.Upsert(metrics.ExitCode, code.String()),
+	)
+	stats.Record(ctx, metrics.MessageFailedCount.M(1))
+
+	ctx, _ = tag.New(ctx,
+		tag.Upsert(metrics.MessageCID, mc.String()),
+		tag.Upsert(metrics.ExitError, tagValue(errMsg)),
+	)
+	stats.Record(ctx, metrics.MessageFailed.M(1))
+
+	time.AfterFunc(time.Duration(m.dc.OrphanReset), func() {
+		stats.Record(ctx, metrics.MessageFailed.M(0))
+	})
+}
+
+// replayError 通过 StateReplay 取得 actor 返回的错误信息
+func (m *Messages) replayError(mc cid.Cid) string {
+	res, err := m.dc.LotusApi.StateReplay(m.ctx, types.EmptyTSK, mc)
+	if err != nil {
+		log.Debugw("StateReplay failed", "cid", mc, "err", err)
+		return ""
+	}
+	return res.Error
+}
+
+// tagValue tag 的值只能是可打印的 ASCII，且不能超过 255
+func tagValue(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return ' '
+		}
+		return r
+	}, s)
+	if len(s) > maxErrorTag {
+		s = s[:maxErrorTag]
+	}
+	return s
+}
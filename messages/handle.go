@@ -0,0 +1,80 @@
+package messages
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// ServeHTTP
+// GET /messages/cost?date=2006-01-02&miner=&format=json|csv
+// date 默认为昨天（UTC）
+func (m *Messages) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	log.Debugw("cost handle", "path", r.URL.Path, "query", r.URL.RawQuery)
+
+	q := r.URL.Query()
+	date := q.Get("date")
+	if date == "" {
+		date = time.Now().UTC().AddDate(0, 0, -1).Format(dateLayout)
+	}
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	miner := q.Get("miner")
+	if miner != "" {
+		maddr, err := address.NewFromString(miner)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		miner = maddr.String()
+	}
+
+	costs, err := m.Daily(date, miner)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch q.Get("format") {
+	case "", "json":
+		data, err := json.Marshal(&costs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(data)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=message-cost-"+date+".csv")
+
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"date", "miner", "method", "count", "base_fee_burn", "overestimation_burn", "miner_tip", "total"})
+		for _, c := range costs {
+			cw.Write([]string{
+				c.Date,
+				c.Miner,
+				c.Method,
+				strconv.FormatInt(c.Count, 10),
+				types.FIL(c.BaseFeeBurn).Unitless(),
+				types.FIL(c.OverEstimationBurn).Unitless(),
+				types.FIL(c.MinerTip).Unitless(),
+				types.FIL(c.Total).Unitless(),
+			})
+		}
+		cw.Flush()
+	default:
+		http.Error(w, "unknown format", http.StatusBadRequest)
+	}
+}
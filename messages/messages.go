@@ -0,0 +1,159 @@
+package messages
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/config"
+	"github.com/gh-efforts/lotus-monitor/methods"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	logging "github.com/ipfs/go-log/v2"
+)
+
+var log = logging.Logger("monitor/messages")
+
+var lastHeightKey = datastore.NewKey("/meta/lastHeight")
+
+const sendersRefresh = time.Minute * 10
+
+// sender 监控 miner 的 owner/worker/control 地址
+type sender struct {
+	miner address.Address
+	typ   string
+}
+
+// Messages 随着 tipset 到来，索引监控地址发出的链上消息（已执行，有 receipt）
+type Messages struct {
+	ctx context.Context
+	dc  *config.DynamicConfig
+	ds  datastore.Batching
+
+	lk   sync.Mutex
+	last abi.ChainEpoch
+
+	senders     map[address.Address][]sender
+	operational map[address.Address]map[address.Address]struct{} //miner 的 worker/control 地址
+	refreshed   time.Time
+	codes       map[address.Address]cid.Cid
+}
+
+func NewMessages(ctx context.Context, dc *config.DynamicConfig, ds datastore.Batching) (*Messages, error) {
+	m := &Messages{
+		ctx:     ctx,
+		dc:      dc,
+		ds:      namespace.Wrap(ds, datastore.NewKey("/messages")),
+		senders: make(map[address.Address][]sender),
+		codes:   make(map[address.Address]cid.Cid),
+	}
+
+	data, err := m.ds.Get(ctx, lastHeightKey)
+	if err != nil && !errors.Is(err, datastore.ErrNotFound) {
+		return nil, err
+	}
+	if err == nil {
+		h, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		m.last = abi.ChainEpoch(h)
+	}
+	log.Infow("loaded messages", "lastHeight", m.last)
+
+	m.watchChain()
+	return m, nil
+}
+
+// refreshSenders 同时记录 ID 地址和 robust 地址，消息的 From 两种都可能出现
+func (m *Messages) refreshSenders() error {
+	if time.Since(m.refreshed) < sendersRefresh {
+		return nil
+	}
+
+	senders := map[address.Address][]sender{}
+	operational := map[address.Address]map[address.Address]struct{}{}
+	add := func(addr address.Address, s sender) {
+		addrs := []address.Address{addr}
+		robust, err := m.dc.Cache.AccountKey(m.ctx, addr)
+		if err != nil {
+			//multisig 等非 account actor 没有 robust 地址
+			log.Debugw("AccountKey failed", "address", addr, "err", err)
+		} else if robust != addr {
+			addrs = append(addrs, robust)
+		}
+
+		for _, a := range addrs {
+			senders[a] = append(senders[a], s)
+			if s.typ == "worker" || s.typ == "control" {
+				if operational[s.miner] == nil {
+					operational[s.miner] = map[address.Address]struct{}{}
+				}
+				operational[s.miner][a] = struct{}{}
+			}
+		}
+	}
+
+	for _, maddr := range m.dc.MinersList() {
+		mi, err := m.dc.Cache.MinerInfo(m.ctx, maddr, types.EmptyTSK)
+		if err != nil {
+			return err
+		}
+
+		add(mi.Owner, sender{miner: maddr, typ: "owner"})
+		add(mi.Worker, sender{miner: maddr, typ: "worker"})
+		for _, c := range mi.ControlAddresses {
+			add(c, sender{miner: maddr, typ: "control"})
+		}
+		if mi.Beneficiary != address.Undef && mi.Beneficiary != mi.Owner {
+			add(mi.Beneficiary, sender{miner: maddr, typ: "beneficiary"})
+		}
+	}
+
+	m.senders = senders
+	m.operational = operational
+	m.refreshed = time.Now()
+	return nil
+}
+
+// sender 多个 miner 共用一个地址时，优先归属到消息的接收 miner
+func (m *Messages) sender(msg *types.Message) (sender, bool) {
+	ss, ok := m.senders[msg.From]
+	if !ok {
+		return sender{}, false
+	}
+	for _, s := range ss {
+		if s.miner == msg.To {
+			return s, true
+		}
+	}
+	return ss[0], true
+}
+
+func (m *Messages) methodName(to address.Address, num abi.MethodNum, tsk types.TipSetKey) string {
+	code, ok := m.codes[to]
+	if !ok {
+		actor, err := m.dc.LotusApi.StateGetActor(m.ctx, to, tsk)
+		if err != nil {
+			log.Debugw("StateGetActor failed", "address", to, "err", err)
+			return methods.Name(cid.Undef, num)
+		}
+		code = actor.Code
+		m.codes[to] = code
+	}
+
+	return methods.Name(code, num)
+}
+
+func (m *Messages) lastHeight() abi.ChainEpoch {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	return m.last
+}
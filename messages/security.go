@@ -0,0 +1,97 @@
+package messages
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/ipfs/go-cid"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+// defaultAllowMethods owner/beneficiary 默认只允许提币
+var defaultAllowMethods = []string{"WithdrawBalance", "WithdrawBalanceExported"}
+
+// operationalMethods worker/control 日常发出的方法。owner 同时是 worker/control 时只跳过这些方法，
+// 转账、ChangeOwnerAddress、ChangeWorkerAddress 等仍然检查
+var operationalMethods = map[string]struct{}{
+	"SubmitWindowedPoSt":         {},
+	"DisputeWindowedPoSt":        {},
+	"PreCommitSector":            {},
+	"PreCommitSectorBatch":       {},
+	"PreCommitSectorBatch2":      {},
+	"ProveCommitSector":          {},
+	"ProveCommitAggregate":       {},
+	"ProveCommitSectors3":        {},
+	"ProveReplicaUpdates":        {},
+	"ProveReplicaUpdates2":       {},
+	"ProveReplicaUpdates3":       {},
+	"DeclareFaults":              {},
+	"DeclareFaultsRecovered":     {},
+	"ExtendSectorExpiration":     {},
+	"ExtendSectorExpiration2":    {},
+	"CompactPartitions":          {},
+	"CompactSectorNumbers":       {},
+	"PublishStorageDeals":        {},
+	"SettleDealPaymentsExported": {},
+}
+
+func (m *Messages) allowMethods() map[string]struct{} {
+	list := m.dc.SecurityAllowMethods
+	if list == nil {
+		list = defaultAllowMethods
+	}
+
+	allow := map[string]struct{}{}
+	for _, method := range list {
+		allow[method] = struct{}{}
+	}
+	return allow
+}
+
+// securityCheck owner/beneficiary 发出的不在白名单中的消息（转账、ChangeOwnerAddress、ChangeWorkerAddress 等）
+// 发出高危事件。地址同时是该 miner 的 worker/control 时，不检查 operationalMethods
+func (m *Messages) securityCheck(mc cid.Cid, msg *types.Message, method string, allow map[string]struct{}) {
+	if _, ok := allow[method]; ok {
+		return
+	}
+
+	ss := m.senders[msg.From]
+	for _, s := range ss {
+		if s.typ != "owner" && s.typ != "beneficiary" {
+			continue
+		}
+		if _, ok := m.operational[s.miner][msg.From]; ok {
+			if _, ok := operationalMethods[method]; ok {
+				log.Debugw("owner is also worker/control, skip operational method", "miner", s.miner, "address", msg.From, "method", method)
+				continue
+			}
+		}
+
+		log.Errorw("unexpected message from owner/beneficiary", "miner", s.miner, "type", s.typ, "from", msg.From, "to", msg.To, "method", method, "value", types.FIL(msg.Value), "cid", mc)
+
+		ctx, _ := tag.New(m.ctx,
+			tag.Upsert(metrics.MinerID, s.miner.String()),
+			tag.Upsert(metrics.AddressType, s.typ),
+			tag.Upsert(metrics.Method, method),
+		)
+		stats.Record(ctx, metrics.SecurityUnexpectedCount.M(1))
+
+		m.recordSecurityEvent(ctx, mc, msg)
+	}
+}
+
+func (m *Messages) recordSecurityEvent(ctx context.Context, mc cid.Cid, msg *types.Message) {
+	ctx, _ = tag.New(ctx,
+		tag.Upsert(metrics.ActorAddress, msg.From.String()),
+		tag.Upsert(metrics.MessageCID, mc.String()),
+		tag.Upsert(metrics.Severity, "high"),
+	)
+	stats.Record(ctx, metrics.SecurityUnexpected.M(1))
+
+	time.AfterFunc(time.Duration(m.dc.OrphanReset), func() {
+		stats.Record(ctx, metrics.SecurityUnexpected.M(0))
+	})
+}
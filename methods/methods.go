@@ -0,0 +1,72 @@
+// 根据 actor code 和 method number 得到方法名，用于 metrics 的 method tag
+
+package methods
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/filecoin-project/lotus/chain/actors"
+	"github.com/ipfs/go-cid"
+
+	//加载内置 actor 的 manifest，GetActorMetaByCode 依赖它
+	_ "github.com/filecoin-project/lotus/build"
+)
+
+var names = map[string]map[abi.MethodNum]string{}
+
+func init() {
+	register(manifest.AccountKey, builtin.MethodsAccount)
+	register(manifest.InitKey, builtin.MethodsInit)
+	register(manifest.CronKey, builtin.MethodsCron)
+	register(manifest.RewardKey, builtin.MethodsReward)
+	register(manifest.MultisigKey, builtin.MethodsMultisig)
+	register(manifest.PaychKey, builtin.MethodsPaych)
+	register(manifest.MarketKey, builtin.MethodsMarket)
+	register(manifest.PowerKey, builtin.MethodsPower)
+	register(manifest.MinerKey, builtin.MethodsMiner)
+	register(manifest.VerifregKey, builtin.MethodsVerifiedRegistry)
+	register(manifest.DatacapKey, builtin.MethodsDatacap)
+	register(manifest.EvmKey, builtin.MethodsEVM)
+	register(manifest.EamKey, builtin.MethodsEAM)
+	register(manifest.PlaceholderKey, builtin.MethodsPlaceholder)
+	register(manifest.EthAccountKey, builtin.MethodsEthAccount)
+}
+
+func register(actor string, methods interface{}) {
+	m := map[abi.MethodNum]string{}
+	v := reflect.ValueOf(methods)
+	for i := 0; i < v.NumField(); i++ {
+		num := abi.MethodNum(v.Field(i).Uint())
+		//同一个 method number 保留第一个（非 Exported）名字
+		if _, ok := m[num]; !ok {
+			m[num] = v.Type().Field(i).Name
+		}
+	}
+	names[actor] = m
+}
+
+// ActorName 返回 actor 类型（storageminer、account ...），未知时返回空
+func ActorName(code cid.Cid) string {
+	name, _, ok := actors.GetActorMetaByCode(code)
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+// Name 返回方法名，0 为 Send，未知的方法返回 method number
+func Name(code cid.Cid, num abi.MethodNum) string {
+	if num == builtin.MethodSend {
+		return "Send"
+	}
+	if m, ok := names[ActorName(code)]; ok {
+		if name, ok := m[num]; ok {
+			return name
+		}
+	}
+	return strconv.FormatUint(uint64(num), 10)
+}
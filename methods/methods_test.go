@@ -0,0 +1,27 @@
+package methods
+
+import (
+	"testing"
+
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/manifest"
+	"github.com/filecoin-project/lotus/chain/actors"
+)
+
+func TestName(t *testing.T) {
+	code, ok := actors.GetActorCodeID(actorstypes.Version12, manifest.MinerKey)
+	if !ok {
+		t.Fatal("miner actor code not found")
+	}
+
+	if n := Name(code, builtin.MethodsMiner.SubmitWindowedPoSt); n != "SubmitWindowedPoSt" {
+		t.Fatalf("unexpected method name: %s", n)
+	}
+	if n := Name(code, builtin.MethodSend); n != "Send" {
+		t.Fatalf("unexpected method name: %s", n)
+	}
+	if n := Name(code, 123456); n != "123456" {
+		t.Fatalf("unexpected method name: %s", n)
+	}
+}
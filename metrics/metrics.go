@@ -11,7 +11,10 @@ import (
 
 // Distribution
 var defaultMillisecondsDistribution = view.Distribution(0.01, 0.05, 0.1, 0.3, 0.6, 0.8, 1, 2, 3, 4, 5, 6, 8, 10, 13, 16, 20, 25, 30, 40, 50, 65, 80, 100, 130, 160, 200, 250, 300, 400, 500, 650, 800, 1000, 2000, 3000, 4000, 5000, 7500, 10000, 20000, 50000, 100_000, 250_000, 500_000, 1000_000)
-var blockTookDurationDistribution = view.Distribution(0, 1, 2, 3, 5, 7, 10, 30, 60, 120) //seconds
+var blockTookDurationDistribution = view.Distribution(0, 1, 2, 3, 5, 7, 10, 30, 60, 120)               //seconds
+var msgLifetimeDistribution = view.Distribution(30, 60, 120, 300, 600, 1800, 3600, 7200, 21600, 86400) //seconds
+var reorgDepthDistribution = view.Distribution(1, 2, 3, 4, 5, 7, 10, 15, 20, 50)                       //epochs
+var blockRewardDistribution = view.Distribution(0, 1, 2, 5, 10, 15, 20, 30, 50, 100)                   //FIL
 
 // Tags
 var (
@@ -24,13 +27,25 @@ var (
 
 	DeadlineIndex, _ = tag.NewKey("deadline_index")
 
+	ApprovedBy, _ = tag.NewKey("approved_by") //beneficiary, nominee
+
 	TaskType, _ = tag.NewKey("task_type")
 
 	LuckyValueDay, _ = tag.NewKey("lucky_value_day") //1day, 7day, 30day
+	Explorer, _      = tag.NewKey("explorer")
+
+	Method, _    = tag.NewKey("method")
+	MsgResult, _ = tag.NewKey("result") //included, replaced, dropped
 
 	BlockCID, _    = tag.NewKey("block_cid")
 	BlockHeight, _ = tag.NewKey("block_height")
+	OrphanCause, _ = tag.NewKey("orphan_cause")
 	ErrorType, _   = tag.NewKey("error_type")
+	CacheName, _   = tag.NewKey("cache")
+	MessageCID, _  = tag.NewKey("message_cid")
+	ExitCode, _    = tag.NewKey("exit_code")
+	ExitError, _   = tag.NewKey("exit_error")
+	Severity, _    = tag.NewKey("severity")
 	RecordType, _  = tag.NewKey("record_type")
 )
 
@@ -50,19 +65,120 @@ var (
 
 	DeadlineCost = stats.Int64("deadline/cost", "proven cost of current deadline (epoch)", "epoch")
 
+	BeneficiaryHasTerm           = stats.Int64("beneficiary/has_term", "beneficiary term in effect (1 beneficiary is not owner, 0 beneficiary is owner without quota)", stats.UnitDimensionless)
+	BeneficiaryQuota             = stats.Float64("beneficiary/quota", "beneficiary quota (FIL)", "FIL")
+	BeneficiaryUsedQuota         = stats.Float64("beneficiary/used_quota", "beneficiary used quota (FIL)", "FIL")
+	BeneficiaryRemainingQuota    = stats.Float64("beneficiary/remaining_quota", "beneficiary remaining quota (FIL)", "FIL")
+	BeneficiaryExpiration        = stats.Int64("beneficiary/expiration", "beneficiary expiration epoch", "epoch")
+	BeneficiaryPending           = stats.Int64("beneficiary/pending", "pending beneficiary change (1 pending, 0 none)", stats.UnitDimensionless)
+	BeneficiaryPendingQuota      = stats.Float64("beneficiary/pending_quota", "new quota of pending beneficiary change (FIL)", "FIL")
+	BeneficiaryPendingExpiration = stats.Int64("beneficiary/pending_expiration", "new expiration epoch of pending beneficiary change", "epoch")
+	BeneficiaryPendingApproved   = stats.Int64("beneficiary/pending_approved", "pending beneficiary change approval (1 approved, 0 not)", stats.UnitDimensionless)
+
 	JobsTimeout = stats.Int64("miner/jobs", "the number of jobs that timed out", stats.UnitDimensionless)
 	JobsNumber  = stats.Int64("miner/jobs_number", "total number of sealing jobs", stats.UnitDimensionless)
 
 	LuckyValue = stats.Float64("lucky_value", "lucky value of miner", stats.UnitDimensionless)
 
+	MiningBlocksMined           = stats.Int64("mining_stats/blocks_mined", "blocks mined in duration from explorer", stats.UnitDimensionless)
+	MiningWeightedBlocksMined   = stats.Int64("mining_stats/weighted_blocks_mined", "weighted blocks mined (win count) in duration from explorer", stats.UnitDimensionless)
+	MiningTotalRewards          = stats.Float64("mining_stats/total_rewards", "total rewards in duration from explorer (FIL)", "FIL")
+	MiningNetworkTotalRewards   = stats.Float64("mining_stats/network_total_rewards", "network total rewards in duration from explorer (FIL)", "FIL")
+	MiningEquivalentMiners      = stats.Float64("mining_stats/equivalent_miners", "equivalent miners from explorer", stats.UnitDimensionless)
+	MiningRewardPerByte         = stats.Float64("mining_stats/reward_per_byte", "reward per byte from explorer", stats.UnitDimensionless)
+	MiningRawBytePowerGrowth    = stats.Float64("mining_stats/raw_byte_power_growth", "raw byte power growth in duration from explorer", stats.UnitBytes)
+	MiningQualityAdjPowerGrowth = stats.Float64("mining_stats/quality_adj_power_growth", "quality adj power growth in duration from explorer", stats.UnitBytes)
+	MiningRawBytePowerDelta     = stats.Float64("mining_stats/raw_byte_power_delta", "raw byte power delta in duration from explorer", stats.UnitBytes)
+	MiningQualityAdjPowerDelta  = stats.Float64("mining_stats/quality_adj_power_delta", "quality adj power delta in duration from explorer", stats.UnitBytes)
+	MiningDurationPercentage    = stats.Int64("mining_stats/duration_percentage", "percentage of duration covered by explorer data", stats.UnitDimensionless)
+	MiningFetchTimestamp        = stats.Int64("mining_stats/fetch_timestamp", "unix timestamp of last successful mining stats fetch", stats.UnitSeconds)
+
+	ExplorerQueueDepth  = stats.Int64("explorer/queue_depth", "number of explorer requests waiting in queue", stats.UnitDimensionless)
+	ExplorerLastSuccess = stats.Int64("explorer/last_success", "unix timestamp of last successful explorer fetch of miner", stats.UnitSeconds)
+
+	LocalExpectedWinsPerDay = stats.Float64("lucky/expected_wins_per_day", "expected wins per day computed from miner power", stats.UnitDimensionless)
+	LocalExpectedWins       = stats.Float64("lucky/expected_wins", "expected wins in window computed from miner power", stats.UnitDimensionless)
+	LocalObservedWins       = stats.Int64("lucky/observed_wins", "observed wins in window from chain data", stats.UnitDimensionless)
+	LocalLuckyValue         = stats.Float64("lucky/local_value", "lucky value computed from chain data", stats.UnitDimensionless)
+
 	BlockOnchain      = stats.Int64("block/on_chain", "counter for block on chain", stats.UnitDimensionless)
 	BlockOrphanCount  = stats.Int64("block/orphan_count", "counter for orphan block", stats.UnitDimensionless)
 	BlockOrphan       = stats.Int64("block/orphan", "mined orphan block", stats.UnitDimensionless)
 	BlockTookDuration = stats.Float64("block/took", "duration of mined a block", stats.UnitSeconds)
+	BlockReward       = stats.Float64("block/reward", "block reward and gas reward of on chain block (FIL)", "FIL")
+	BlockRewardTotal  = stats.Float64("block/reward_total", "cumulative FIL earned by on chain blocks, reduced when a confirmed block is reverted", "FIL")
+	BlockWinCount     = stats.Int64("block/win_count", "win count of on chain block", stats.UnitDimensionless)
+
+	ControlDays       = stats.Float64("control/days", "control address available days", stats.UnitDimensionless)
+	ControlBurnPerDay = stats.Float64("control/burn_per_day", "FIL spent on gas per day by control address", stats.UnitDimensionless)
+
+	MessageCount              = stats.Int64("message/count", "number of on-chain messages sent by miner addresses", stats.UnitDimensionless)
+	MessageBaseFeeBurn        = stats.Float64("message/base_fee_burn", "FIL burned by base fee of on-chain messages", stats.UnitDimensionless)
+	MessageOverEstimationBurn = stats.Float64("message/overestimation_burn", "FIL burned by gas overestimation of on-chain messages", stats.UnitDimensionless)
+	MessageMinerTip           = stats.Float64("message/miner_tip", "FIL paid as miner tip of on-chain messages", stats.UnitDimensionless)
+
+	MessageFailed      = stats.Int64("message/failed", "on-chain message failed with non-zero exit code", stats.UnitDimensionless)
+	MessageFailedCount = stats.Int64("message/failed_count", "number of failed on-chain messages", stats.UnitDimensionless)
+
+	MessageIndexSkipped = stats.Int64("message/index_skipped", "number of epochs skipped by message indexer because the gap exceeds finality", stats.UnitDimensionless)
+
+	SecurityUnexpected      = stats.Int64("security/unexpected_message", "message from owner/beneficiary not on allow-list", stats.UnitDimensionless)
+	SecurityUnexpectedCount = stats.Int64("security/unexpected_message_count", "number of messages from owner/beneficiary not on allow-list", stats.UnitDimensionless)
+
+	ChainHeight            = stats.Int64("chain/height", "chain head height", stats.UnitDimensionless)
+	ChainHeadLag           = stats.Float64("chain/head_lag", "seconds between wall clock and expected time of head (genesis + height*30s)", stats.UnitSeconds)
+	ChainBlocksPerTipSet   = stats.Int64("chain/blocks_per_tipset", "number of blocks in head tipset", stats.UnitDimensionless)
+	ChainAvgBlocksPerEpoch = stats.Float64("chain/avg_blocks_per_epoch", "average number of blocks per epoch in last hour", stats.UnitDimensionless)
+	ChainNullRoundRate     = stats.Float64("chain/null_round_rate", "rate of null rounds in last hour", stats.UnitDimensionless)
+	ChainBaseFee           = stats.Float64("chain/base_fee", "base fee of head in attoFIL", stats.UnitDimensionless)
+	ChainNetworkRawPower   = stats.Float64("chain/network_raw_power", "network raw byte power", stats.UnitBytes)
+	ChainNetworkQAPower    = stats.Float64("chain/network_qa_power", "network quality adjusted power", stats.UnitBytes)
+	ChainCirculatingSupply = stats.Float64("chain/circulating_supply", "FIL circulating supply", stats.UnitDimensionless)
+	ChainNetworkVersion    = stats.Int64("chain/network_version", "current network version", stats.UnitDimensionless)
+
+	ChainReorgDepth            = stats.Int64("chain/reorg_depth", "depth of chain reorg in epochs", stats.UnitDimensionless)
+	ChainRevertedTipSets       = stats.Int64("chain/reverted_tipsets", "number of reverted tipsets", stats.UnitDimensionless)
+	SuggestedOrphanCheckHeight = stats.Int64("blocks/suggested_orphan_check_height", "suggested orphanCheckHeight from max reorg depth observed in the last 24h", stats.UnitDimensionless)
+
+	VerifregClaims                   = stats.Int64("verifreg/claims", "number of active verified claims", stats.UnitDimensionless)
+	VerifregClaimsSize               = stats.Int64("verifreg/claims_size", "padded size of active verified claims", stats.UnitBytes)
+	VerifregClaimsExpiring           = stats.Int64("verifreg/claims_expiring", "number of claims reaching TermMax within configured days", stats.UnitDimensionless)
+	VerifregClaimsExpiringSize       = stats.Int64("verifreg/claims_expiring_size", "padded size of claims reaching TermMax within configured days", stats.UnitBytes)
+	VerifregAllocations              = stats.Int64("verifreg/allocations", "number of pending allocations not yet expired", stats.UnitDimensionless)
+	VerifregAllocationsSize          = stats.Int64("verifreg/allocations_size", "padded size of pending allocations not yet expired", stats.UnitBytes)
+	VerifregAllocationsExpiring      = stats.Int64("verifreg/allocations_expiring", "number of pending allocations expiring within configured days", stats.UnitDimensionless)
+	VerifregAllocationsExpiringSize  = stats.Int64("verifreg/allocations_expiring_size", "padded size of pending allocations expiring within configured days", stats.UnitBytes)
+	VerifregAllocationsExpired       = stats.Int64("verifreg/allocations_expired", "number of expired allocations never claimed", stats.UnitDimensionless)
+	VerifregAllocationsExpiredSize   = stats.Int64("verifreg/allocations_expired_size", "padded size of expired allocations never claimed", stats.UnitBytes)
+	VerifregAllocationNextExpiration = stats.Int64("verifreg/allocation_next_expiration", "epochs until the earliest pending allocation expires", "epoch")
 
-	ControlDays = stats.Float64("control/days", "control address available days", stats.UnitDimensionless)
+	DealsActive        = stats.Int64("deals/active", "number of active deals indexed by monitor, deals published before startHeight or first startup are not included", stats.UnitDimensionless)
+	DealsActiveSize    = stats.Int64("deals/active_size", "padded piece size of active deals indexed by monitor, deals published before startHeight or first startup are not included", stats.UnitBytes)
+	DealsPending       = stats.Int64("deals/pending", "number of published but not activated deals", stats.UnitDimensionless)
+	DealsPendingSize   = stats.Int64("deals/pending_size", "padded piece size of published but not activated deals", stats.UnitBytes)
+	DealsNearStart     = stats.Int64("deals/near_start", "number of not activated deals close to their start epoch", stats.UnitDimensionless)
+	DealsNearStartSize = stats.Int64("deals/near_start_size", "padded piece size of not activated deals close to their start epoch", stats.UnitBytes)
+	DealsSlashed       = stats.Int64("deals/slashed", "number of slashed deals before their end epoch", stats.UnitDimensionless)
+	DealsSlashedSize   = stats.Int64("deals/slashed_size", "padded piece size of slashed deals before their end epoch", stats.UnitBytes)
+	DealsMissed        = stats.Int64("deals/missed", "number of deals reaching start epoch without activation", stats.UnitDimensionless)
 
-	MpoolMsgNumber = stats.Int64("mpool/msg", "number of messages in mpool for specified address", stats.UnitDimensionless)
+	TerminationExposure = stats.Float64("termination/exposure", "estimated termination fee of all sectors (FIL)", "FIL")
+
+	//只用于历史数据回填，每一步的出块数，和实时的累计 BlockWinCount 区分
+	BackfillBlockWins = stats.Int64("backfill/block_wins", "win count of miner since previous backfill step", stats.UnitDimensionless)
+
+	CollectHeight = stats.Int64("collect/height", "height of tipset the latest event-driven round of collector was computed at", stats.UnitDimensionless)
+
+	CacheHit  = stats.Int64("cache/hit", "number of shared cache hits", stats.UnitDimensionless)
+	CacheMiss = stats.Int64("cache/miss", "number of shared cache misses", stats.UnitDimensionless)
+
+	MpoolMsgNumber   = stats.Int64("mpool/msg", "number of messages in mpool for specified address", stats.UnitDimensionless)
+	MpoolMsgByMethod = stats.Int64("mpool/msg_by_method", "number of messages in mpool for specified address by method", stats.UnitDimensionless)
+	MpoolUnderpriced = stats.Int64("mpool/underpriced", "number of messages in mpool whose GasFeeCap is below base fee", stats.UnitDimensionless)
+	MpoolOldestAge   = stats.Float64("mpool/oldest_age", "seconds since oldest pending message of address was first seen", stats.UnitSeconds)
+	MpoolNonceGap    = stats.Int64("mpool/nonce_gap", "number of missing nonces between actor nonce and highest pending nonce", stats.UnitDimensionless)
+	MpoolMsgLifetime = stats.Float64("mpool/msg_lifetime", "seconds from message entering mpool until inclusion or drop", stats.UnitSeconds)
+	MpoolNonceAhead  = stats.Int64("mpool/nonce_ahead", "MpoolGetNonce minus actor nonce", stats.UnitDimensionless)
 
 	SelfError          = stats.Int64("self/error", "couter for monitor error", stats.UnitDimensionless)
 	SelfRecordDuration = stats.Float64("self/record", "duration of every record", stats.UnitMilliseconds)
@@ -117,6 +233,51 @@ var (
 		Measure:     DeadlineCost,
 		TagKeys:     []tag.Key{MinerID, DeadlineIndex},
 	}
+	BeneficiaryHasTermView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     BeneficiaryHasTerm,
+		TagKeys:     []tag.Key{MinerID, ActorAddress},
+	}
+	BeneficiaryQuotaView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     BeneficiaryQuota,
+		TagKeys:     []tag.Key{MinerID, ActorAddress},
+	}
+	BeneficiaryUsedQuotaView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     BeneficiaryUsedQuota,
+		TagKeys:     []tag.Key{MinerID, ActorAddress},
+	}
+	BeneficiaryRemainingQuotaView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     BeneficiaryRemainingQuota,
+		TagKeys:     []tag.Key{MinerID, ActorAddress},
+	}
+	BeneficiaryExpirationView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     BeneficiaryExpiration,
+		TagKeys:     []tag.Key{MinerID, ActorAddress},
+	}
+	BeneficiaryPendingView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     BeneficiaryPending,
+		TagKeys:     []tag.Key{MinerID},
+	}
+	BeneficiaryPendingQuotaView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     BeneficiaryPendingQuota,
+		TagKeys:     []tag.Key{MinerID, ActorAddress},
+	}
+	BeneficiaryPendingExpirationView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     BeneficiaryPendingExpiration,
+		TagKeys:     []tag.Key{MinerID, ActorAddress},
+	}
+	BeneficiaryPendingApprovedView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     BeneficiaryPendingApproved,
+		TagKeys:     []tag.Key{MinerID, ApprovedBy},
+	}
 	JobsTimeoutView = &view.View{
 		Aggregation: view.LastValue(),
 		Measure:     JobsTimeout,
@@ -130,17 +291,107 @@ var (
 	LuckyValueView = &view.View{
 		Aggregation: view.LastValue(),
 		Measure:     LuckyValue,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	MiningBlocksMinedView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     MiningBlocksMined,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	MiningWeightedBlocksMinedView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     MiningWeightedBlocksMined,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	MiningTotalRewardsView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     MiningTotalRewards,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	MiningNetworkTotalRewardsView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     MiningNetworkTotalRewards,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	MiningEquivalentMinersView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     MiningEquivalentMiners,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	MiningRewardPerByteView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     MiningRewardPerByte,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	MiningRawBytePowerGrowthView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     MiningRawBytePowerGrowth,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	MiningQualityAdjPowerGrowthView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     MiningQualityAdjPowerGrowth,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	MiningRawBytePowerDeltaView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     MiningRawBytePowerDelta,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	MiningQualityAdjPowerDeltaView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     MiningQualityAdjPowerDelta,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	MiningDurationPercentageView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     MiningDurationPercentage,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	MiningFetchTimestampView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     MiningFetchTimestamp,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay, Explorer},
+	}
+	ExplorerQueueDepthView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     ExplorerQueueDepth,
+	}
+	ExplorerLastSuccessView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     ExplorerLastSuccess,
+		TagKeys:     []tag.Key{MinerID, Explorer},
+	}
+	LocalExpectedWinsPerDayView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     LocalExpectedWinsPerDay,
+		TagKeys:     []tag.Key{MinerID},
+	}
+	LocalExpectedWinsView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     LocalExpectedWins,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay},
+	}
+	LocalObservedWinsView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     LocalObservedWins,
+		TagKeys:     []tag.Key{MinerID, LuckyValueDay},
+	}
+	LocalLuckyValueView = &view.View{
+		Aggregation: view.LastValue(),
+		Measure:     LocalLuckyValue,
 		TagKeys:     []tag.Key{MinerID, LuckyValueDay},
 	}
+	//Sum 而不是 Count，已确认的块被 revert 时记录 -1
 	BlockOnchainView = &view.View{
 		Measure:     BlockOnchain,
-		Aggregation: view.Count(),
+		Aggregation: view.Sum(),
 		TagKeys:     []tag.Key{MinerID},
 	}
 	BlockOrphanCountView = &view.View{
 		Measure:     BlockOrphanCount,
 		Aggregation: view.Count(),
-		TagKeys:     []tag.Key{MinerID},
+		TagKeys:     []tag.Key{MinerID, OrphanCause},
 	}
 	BlockOrphanView = &view.View{
 		Measure:     BlockOrphan,
@@ -152,16 +403,287 @@ var (
 		Aggregation: blockTookDurationDistribution,
 		TagKeys:     []tag.Key{MinerID},
 	}
+	BlockRewardView = &view.View{
+		Measure:     BlockReward,
+		Aggregation: blockRewardDistribution,
+		TagKeys:     []tag.Key{MinerID},
+	}
+	BlockRewardTotalView = &view.View{
+		Measure:     BlockRewardTotal,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	BlockWinCountView = &view.View{
+		Measure:     BlockWinCount,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{MinerID},
+	}
 	ControlDaysView = &view.View{
 		Measure:     ControlDays,
 		Aggregation: view.LastValue(),
-		TagKeys:     []tag.Key{MinerID, ActorAddress},
+		TagKeys:     []tag.Key{MinerID, ActorAddress, AddressType},
+	}
+	ControlBurnPerDayView = &view.View{
+		Measure:     ControlBurnPerDay,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID, ActorAddress, AddressType},
+	}
+	MessageCountView = &view.View{
+		Measure:     MessageCount,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{MinerID, Method, AddressType},
+	}
+	MessageBaseFeeBurnView = &view.View{
+		Measure:     MessageBaseFeeBurn,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{MinerID, Method, AddressType},
+	}
+	MessageOverEstimationBurnView = &view.View{
+		Measure:     MessageOverEstimationBurn,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{MinerID, Method, AddressType},
+	}
+	MessageMinerTipView = &view.View{
+		Measure:     MessageMinerTip,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{MinerID, Method, AddressType},
+	}
+	MessageFailedView = &view.View{
+		Measure:     MessageFailed,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID, Method, AddressType, ExitCode, MessageCID, ExitError},
+	}
+	MessageFailedCountView = &view.View{
+		Measure:     MessageFailedCount,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{MinerID, Method, ExitCode},
+	}
+	MessageIndexSkippedView = &view.View{
+		Measure:     MessageIndexSkipped,
+		Aggregation: view.Sum(),
+	}
+	SecurityUnexpectedView = &view.View{
+		Measure:     SecurityUnexpected,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID, AddressType, Method, ActorAddress, MessageCID, Severity},
+	}
+	SecurityUnexpectedCountView = &view.View{
+		Measure:     SecurityUnexpectedCount,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{MinerID, AddressType, Method},
+	}
+	ChainHeightView = &view.View{
+		Measure:     ChainHeight,
+		Aggregation: view.LastValue(),
+	}
+	ChainHeadLagView = &view.View{
+		Measure:     ChainHeadLag,
+		Aggregation: view.LastValue(),
+	}
+	ChainBlocksPerTipSetView = &view.View{
+		Measure:     ChainBlocksPerTipSet,
+		Aggregation: view.LastValue(),
+	}
+	ChainAvgBlocksPerEpochView = &view.View{
+		Measure:     ChainAvgBlocksPerEpoch,
+		Aggregation: view.LastValue(),
+	}
+	ChainNullRoundRateView = &view.View{
+		Measure:     ChainNullRoundRate,
+		Aggregation: view.LastValue(),
+	}
+	ChainBaseFeeView = &view.View{
+		Measure:     ChainBaseFee,
+		Aggregation: view.LastValue(),
+	}
+	ChainNetworkRawPowerView = &view.View{
+		Measure:     ChainNetworkRawPower,
+		Aggregation: view.LastValue(),
+	}
+	ChainNetworkQAPowerView = &view.View{
+		Measure:     ChainNetworkQAPower,
+		Aggregation: view.LastValue(),
+	}
+	ChainCirculatingSupplyView = &view.View{
+		Measure:     ChainCirculatingSupply,
+		Aggregation: view.LastValue(),
+	}
+	ChainNetworkVersionView = &view.View{
+		Measure:     ChainNetworkVersion,
+		Aggregation: view.LastValue(),
+	}
+	ChainReorgDepthView = &view.View{
+		Measure:     ChainReorgDepth,
+		Aggregation: reorgDepthDistribution,
+	}
+	ChainRevertedTipSetsView = &view.View{
+		Measure:     ChainRevertedTipSets,
+		Aggregation: view.Sum(),
+	}
+	SuggestedOrphanCheckHeightView = &view.View{
+		Measure:     SuggestedOrphanCheckHeight,
+		Aggregation: view.LastValue(),
+	}
+	VerifregClaimsView = &view.View{
+		Measure:     VerifregClaims,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	VerifregClaimsSizeView = &view.View{
+		Measure:     VerifregClaimsSize,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	VerifregClaimsExpiringView = &view.View{
+		Measure:     VerifregClaimsExpiring,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	VerifregClaimsExpiringSizeView = &view.View{
+		Measure:     VerifregClaimsExpiringSize,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	VerifregAllocationsView = &view.View{
+		Measure:     VerifregAllocations,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	VerifregAllocationsSizeView = &view.View{
+		Measure:     VerifregAllocationsSize,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	VerifregAllocationsExpiringView = &view.View{
+		Measure:     VerifregAllocationsExpiring,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	VerifregAllocationsExpiringSizeView = &view.View{
+		Measure:     VerifregAllocationsExpiringSize,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	VerifregAllocationsExpiredView = &view.View{
+		Measure:     VerifregAllocationsExpired,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	VerifregAllocationsExpiredSizeView = &view.View{
+		Measure:     VerifregAllocationsExpiredSize,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	VerifregAllocationNextExpirationView = &view.View{
+		Measure:     VerifregAllocationNextExpiration,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	DealsActiveView = &view.View{
+		Measure:     DealsActive,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	DealsActiveSizeView = &view.View{
+		Measure:     DealsActiveSize,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	DealsPendingView = &view.View{
+		Measure:     DealsPending,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	DealsPendingSizeView = &view.View{
+		Measure:     DealsPendingSize,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	DealsNearStartView = &view.View{
+		Measure:     DealsNearStart,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	DealsNearStartSizeView = &view.View{
+		Measure:     DealsNearStartSize,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	DealsSlashedView = &view.View{
+		Measure:     DealsSlashed,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	DealsSlashedSizeView = &view.View{
+		Measure:     DealsSlashedSize,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	DealsMissedView = &view.View{
+		Measure:     DealsMissed,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	TerminationExposureView = &view.View{
+		Measure:     TerminationExposure,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	BackfillBlockWinsView = &view.View{
+		Measure:     BackfillBlockWins,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{MinerID},
+	}
+	CollectHeightView = &view.View{
+		Measure:     CollectHeight,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{RecordType},
+	}
+	CacheHitView = &view.View{
+		Measure:     CacheHit,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{CacheName},
+	}
+	CacheMissView = &view.View{
+		Measure:     CacheMiss,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{CacheName},
 	}
 	MpoolMsgNumberView = &view.View{
 		Measure:     MpoolMsgNumber,
 		Aggregation: view.LastValue(),
 		TagKeys:     []tag.Key{ActorAddress},
 	}
+	MpoolMsgByMethodView = &view.View{
+		Measure:     MpoolMsgByMethod,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{ActorAddress, Method},
+	}
+	MpoolUnderpricedView = &view.View{
+		Measure:     MpoolUnderpriced,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{ActorAddress, Method},
+	}
+	MpoolOldestAgeView = &view.View{
+		Measure:     MpoolOldestAge,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{ActorAddress},
+	}
+	MpoolNonceGapView = &view.View{
+		Measure:     MpoolNonceGap,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{ActorAddress},
+	}
+	MpoolMsgLifetimeView = &view.View{
+		Measure:     MpoolMsgLifetime,
+		Aggregation: msgLifetimeDistribution,
+		TagKeys:     []tag.Key{ActorAddress, Method, MsgResult},
+	}
+	MpoolNonceAheadView = &view.View{
+		Measure:     MpoolNonceAhead,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{ActorAddress},
+	}
 	SelfErrorView = &view.View{
 		Measure:     SelfError,
 		Aggregation: view.Count(),
@@ -184,15 +706,98 @@ var Views = []*view.View{
 	MinerQualityAdjPowerView,
 	MinerAvailableBalanceView,
 	DeadlineCostView,
+	BeneficiaryHasTermView,
+	BeneficiaryQuotaView,
+	BeneficiaryUsedQuotaView,
+	BeneficiaryRemainingQuotaView,
+	BeneficiaryExpirationView,
+	BeneficiaryPendingView,
+	BeneficiaryPendingQuotaView,
+	BeneficiaryPendingExpirationView,
+	BeneficiaryPendingApprovedView,
 	JobsTimeoutView,
 	JobsNumberView,
 	LuckyValueView,
+	MiningBlocksMinedView,
+	MiningWeightedBlocksMinedView,
+	MiningTotalRewardsView,
+	MiningNetworkTotalRewardsView,
+	MiningEquivalentMinersView,
+	MiningRewardPerByteView,
+	MiningRawBytePowerGrowthView,
+	MiningQualityAdjPowerGrowthView,
+	MiningRawBytePowerDeltaView,
+	MiningQualityAdjPowerDeltaView,
+	MiningDurationPercentageView,
+	MiningFetchTimestampView,
+	ExplorerQueueDepthView,
+	ExplorerLastSuccessView,
+	LocalExpectedWinsPerDayView,
+	LocalExpectedWinsView,
+	LocalObservedWinsView,
+	LocalLuckyValueView,
 	BlockOnchainView,
 	BlockOrphanCountView,
 	BlockOrphanView,
 	BlockTookDurationView,
+	BlockRewardView,
+	BlockRewardTotalView,
+	BlockWinCountView,
 	ControlDaysView,
+	ControlBurnPerDayView,
+	MessageCountView,
+	MessageBaseFeeBurnView,
+	MessageOverEstimationBurnView,
+	MessageMinerTipView,
+	MessageFailedView,
+	MessageFailedCountView,
+	MessageIndexSkippedView,
+	SecurityUnexpectedView,
+	SecurityUnexpectedCountView,
+	ChainHeightView,
+	ChainHeadLagView,
+	ChainBlocksPerTipSetView,
+	ChainAvgBlocksPerEpochView,
+	ChainNullRoundRateView,
+	ChainBaseFeeView,
+	ChainNetworkRawPowerView,
+	ChainNetworkQAPowerView,
+	ChainCirculatingSupplyView,
+	ChainNetworkVersionView,
+	ChainReorgDepthView,
+	ChainRevertedTipSetsView,
+	SuggestedOrphanCheckHeightView,
+	VerifregClaimsView,
+	VerifregClaimsSizeView,
+	VerifregClaimsExpiringView,
+	VerifregClaimsExpiringSizeView,
+	VerifregAllocationsView,
+	VerifregAllocationsSizeView,
+	VerifregAllocationsExpiringView,
+	VerifregAllocationsExpiringSizeView,
+	VerifregAllocationsExpiredView,
+	VerifregAllocationsExpiredSizeView,
+	VerifregAllocationNextExpirationView,
+	DealsActiveView,
+	DealsActiveSizeView,
+	DealsPendingView,
+	DealsPendingSizeView,
+	DealsNearStartView,
+	DealsNearStartSizeView,
+	DealsSlashedView,
+	DealsSlashedSizeView,
+	DealsMissedView,
+	TerminationExposureView,
+	CollectHeightView,
+	CacheHitView,
+	CacheMissView,
 	MpoolMsgNumberView,
+	MpoolMsgByMethodView,
+	MpoolUnderpricedView,
+	MpoolOldestAgeView,
+	MpoolNonceGapView,
+	MpoolNonceAheadView,
+	MpoolMsgLifetimeView,
 	SelfErrorView,
 	SelfRecordDurationView,
 }
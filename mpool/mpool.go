@@ -2,12 +2,14 @@ package mpool
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/gh-efforts/lotus-monitor/config"
 	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log/v2"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
@@ -15,11 +17,25 @@ import (
 
 var log = logging.Logger("monitor/mpool")
 
+const defaultSyncInterval = time.Minute * 30
+
 type Mpool struct {
 	ctx context.Context
 	dc  *config.DynamicConfig
 
-	resolves map[address.Address]address.Address
+	methods map[address.Address]map[string]struct{}
+
+	clk   sync.Mutex
+	codes map[address.Address]cid.Cid
+
+	//MpoolSub 维护的监控地址的 pending 消息
+	lk      sync.Mutex
+	tracked map[address.Address]struct{}
+	pending map[cid.Cid]pendingMsg
+	removed []removedMsg
+
+	//Run 和重新订阅都会调用 sync
+	syncLk sync.Mutex
 }
 
 func NewMpool(ctx context.Context, dc *config.DynamicConfig) *Mpool {
@@ -27,18 +43,36 @@ func NewMpool(ctx context.Context, dc *config.DynamicConfig) *Mpool {
 		ctx: ctx,
 		dc:  dc,
 
-		resolves: make(map[address.Address]address.Address),
+		methods: make(map[address.Address]map[string]struct{}),
+		codes:   make(map[address.Address]cid.Cid),
+		tracked: make(map[address.Address]struct{}),
+		pending: make(map[cid.Cid]pendingMsg),
 	}
 }
 
 func (m *Mpool) Run() {
 	go func() {
+		if err := m.updateTracked(); err != nil {
+			log.Errorw("updateTracked failed", "err", err)
+			metrics.RecordError(m.ctx, "mpool/updateTracked")
+		}
+		m.sync()
+		m.watchMpool()
 		m.record()
+
+		syncInterval := time.Duration(m.dc.RecordInterval.MpoolSync)
+		if syncInterval == 0 {
+			syncInterval = defaultSyncInterval
+		}
+
 		t := time.NewTicker(time.Duration(m.dc.RecordInterval.Mpool))
+		s := time.NewTicker(syncInterval)
 		for {
 			select {
 			case <-t.C:
 				m.record()
+			case <-s.C:
+				m.sync()
 			case <-m.ctx.Done():
 				return
 			}
@@ -55,69 +89,79 @@ func (m *Mpool) record() {
 		log.Errorw("mpool record failed", "err", err)
 		metrics.RecordError(m.ctx, "mpool/record")
 	}
+	m.classifyRemoved()
 }
 
 func (m *Mpool) _record() error {
+	if err := m.updateTracked(); err != nil {
+		return err
+	}
+
 	actors := map[address.Address]int64{}
+	pending := map[address.Address][]pendingMsg{}
+
+	m.lk.Lock()
+	for a := range m.tracked {
+		actors[a] = 0
+	}
+	for _, p := range m.pending {
+		from := p.msg.Message.From
+		actors[from] += 1
+		pending[from] = append(pending[from], p)
+	}
+	m.lk.Unlock()
+
+	head, err := m.dc.LotusApi.ChainHead(m.ctx)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range actors {
+		ctx, _ := tag.New(m.ctx,
+			tag.Upsert(metrics.ActorAddress, k.String()),
+		)
+		stats.Record(ctx, metrics.MpoolMsgNumber.M(v))
+
+		if err := m.stuckRecord(ctx, k, pending[k], head); err != nil {
+			log.Errorw("stuckRecord failed", "address", k, "err", err)
+			metrics.RecordError(m.ctx, "mpool/stuckRecord")
+		}
+	}
+
+	return nil
+}
+
+// updateTracked 更新需要跟踪的 worker/control 地址（robust 地址）
+func (m *Mpool) updateTracked() error {
+	actors := map[address.Address]struct{}{}
 
 	miners := m.dc.MinersList()
 	for _, maddr := range miners {
-		mi, err := m.dc.LotusApi.StateMinerInfo(m.ctx, maddr, types.EmptyTSK)
+		mi, err := m.dc.Cache.MinerInfo(m.ctx, maddr, types.EmptyTSK)
 		if err != nil {
 			return err
 		}
 
-		worker, err := m.resolve(mi.Worker)
+		worker, err := m.dc.Cache.AccountKey(m.ctx, mi.Worker)
 		if err != nil {
 			return err
 		}
 
-		actors[worker] = 0
+		actors[worker] = struct{}{}
 
 		for _, c := range mi.ControlAddresses {
-			d, err := m.resolve(c)
+			d, err := m.dc.Cache.AccountKey(m.ctx, c)
 			if err != nil {
 				return err
 			}
 
-			actors[d] = 0
+			actors[d] = struct{}{}
 		}
 	}
 
-	msgs, err := m.dc.LotusApi.MpoolPending(m.ctx, types.EmptyTSK)
-	if err != nil {
-		return err
-	}
-
-	for _, v := range msgs {
-		if _, has := actors[v.Message.From]; has {
-			actors[v.Message.From] += 1
-		}
-	}
-
-	for k, v := range actors {
-		ctx, _ := tag.New(m.ctx,
-			tag.Upsert(metrics.ActorAddress, k.String()),
-		)
-		stats.Record(ctx, metrics.MpoolMsgNumber.M(v))
-	}
+	m.lk.Lock()
+	m.tracked = actors
+	m.lk.Unlock()
 
 	return nil
 }
-
-func (m *Mpool) resolve(id address.Address) (address.Address, error) {
-	var addr address.Address
-	addr, has := m.resolves[id]
-	if has {
-		return addr, nil
-	}
-
-	addr, err := m.dc.LotusApi.StateAccountKey(m.ctx, id, types.EmptyTSK)
-	if err != nil {
-		return address.Address{}, err
-	}
-
-	m.resolves[id] = addr
-
-	return addr, nil
-}
@@ -0,0 +1,211 @@
+package mpool
+
+import (
+	"errors"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/ipfs/go-cid"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+// 消息离开 mpool 的原因
+const (
+	ResultIncluded = "included"
+	ResultReplaced = "replaced"
+	ResultDropped  = "dropped"
+)
+
+// classifyDelay 消息被打包后，下一个 tipset 才有 receipt，
+// 离开 mpool 一段时间后再用 StateSearchMsg 判断是上链还是被丢弃
+const classifyDelay = time.Second * builtin.EpochDurationSeconds * 3
+
+type pendingMsg struct {
+	msg    *types.SignedMessage
+	method string
+	seen   time.Time //第一次看到消息的时间
+}
+
+type removedMsg struct {
+	pendingMsg
+	removed time.Time
+}
+
+// watchMpool 订阅 MpoolSub，只保留监控地址的消息
+func (m *Mpool) watchMpool() {
+	go func() {
+		for {
+			if err := m.mpoolSub(); err != nil {
+				log.Errorw("mpoolSub failed", "err", err)
+				metrics.RecordError(m.ctx, "mpool/mpoolSub")
+			}
+
+			select {
+			case <-time.After(time.Second * 10):
+				log.Info("resubscribe mpool...")
+				m.sync()
+			case <-m.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (m *Mpool) mpoolSub() error {
+	updates, err := m.dc.LotusApi.MpoolSub(m.ctx)
+	if err != nil {
+		return err
+	}
+
+	for u := range updates {
+		switch u.Type {
+		case api.MpoolAdd:
+			m.add(u.Message, time.Now())
+		case api.MpoolRemove:
+			m.remove(u.Message.Cid(), time.Now())
+		}
+	}
+
+	if m.ctx.Err() != nil {
+		return nil
+	}
+	return errors.New("mpool sub channel closed")
+}
+
+func (m *Mpool) isTracked(sm *types.SignedMessage) bool {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	_, ok := m.tracked[sm.Message.From]
+	return ok
+}
+
+// add 相同 from 和 nonce 的旧消息视为被替换
+func (m *Mpool) add(sm *types.SignedMessage, seen time.Time) {
+	if !m.isTracked(sm) {
+		return
+	}
+	method := m.methodName(m.ctx, sm.Message.To, sm.Message.Method, types.EmptyTSK)
+	c := sm.Cid()
+
+	m.lk.Lock()
+	if _, ok := m.pending[c]; ok {
+		m.lk.Unlock()
+		return
+	}
+	var replaced []pendingMsg
+	for k, p := range m.pending {
+		if p.msg.Message.From == sm.Message.From && p.msg.Message.Nonce == sm.Message.Nonce {
+			replaced = append(replaced, p)
+			delete(m.pending, k)
+		}
+	}
+	m.pending[c] = pendingMsg{msg: sm, method: method, seen: seen}
+	m.lk.Unlock()
+
+	for _, p := range replaced {
+		log.Debugw("message replaced", "old", p.msg.Cid(), "new", c)
+		m.recordLifetime(p, seen, ResultReplaced)
+	}
+}
+
+func (m *Mpool) remove(c cid.Cid, removed time.Time) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	p, ok := m.pending[c]
+	if !ok {
+		return
+	}
+	delete(m.pending, c)
+	m.removed = append(m.removed, removedMsg{pendingMsg: p, removed: removed})
+}
+
+// sync 用 MpoolPending 全量同步，修正订阅断开或漏掉的消息；
+// 快照之后通过 MpoolSub 加入的消息不在快照中，不能当作已离开 mpool
+func (m *Mpool) sync() {
+	m.syncLk.Lock()
+	defer m.syncLk.Unlock()
+
+	stop := metrics.Timer(m.ctx, "mpool/sync")
+	defer stop()
+
+	snapshot := time.Now()
+	msgs, err := m.dc.LotusApi.MpoolPending(m.ctx, types.EmptyTSK)
+	if err != nil {
+		log.Errorw("MpoolPending failed", "err", err)
+		metrics.RecordError(m.ctx, "mpool/sync")
+		return
+	}
+
+	current := map[cid.Cid]struct{}{}
+	for _, sm := range msgs {
+		if !m.isTracked(sm) {
+			continue
+		}
+		current[sm.Cid()] = struct{}{}
+		m.add(sm, snapshot)
+	}
+
+	var stale []cid.Cid
+	m.lk.Lock()
+	for c, p := range m.pending {
+		if _, ok := current[c]; !ok && p.seen.Before(snapshot) {
+			stale = append(stale, c)
+		}
+	}
+	m.lk.Unlock()
+
+	for _, c := range stale {
+		m.remove(c, snapshot)
+	}
+	log.Debugw("mpool sync", "pending", len(current), "stale", len(stale))
+}
+
+// classifyRemoved 判断离开 mpool 的消息是否上链，记录消息在 mpool 中的时间
+func (m *Mpool) classifyRemoved() {
+	m.lk.Lock()
+	var ready []removedMsg
+	var rest []removedMsg
+	for _, r := range m.removed {
+		if time.Since(r.removed) >= classifyDelay {
+			ready = append(ready, r)
+		} else {
+			rest = append(rest, r)
+		}
+	}
+	m.removed = rest
+	m.lk.Unlock()
+
+	for _, r := range ready {
+		limit := abi.ChainEpoch(time.Since(r.seen)/(time.Second*builtin.EpochDurationSeconds)) + 1
+		lookup, err := m.dc.LotusApi.StateSearchMsg(m.ctx, types.EmptyTSK, r.msg.Cid(), limit, true)
+		if err != nil {
+			log.Warnw("StateSearchMsg failed", "cid", r.msg.Cid(), "err", err)
+			m.lk.Lock()
+			m.removed = append(m.removed, r)
+			m.lk.Unlock()
+			continue
+		}
+
+		result := ResultDropped
+		if lookup != nil {
+			result = ResultIncluded
+		}
+		m.recordLifetime(r.pendingMsg, r.removed, result)
+	}
+}
+
+func (m *Mpool) recordLifetime(p pendingMsg, end time.Time, result string) {
+	ctx, _ := tag.New(m.ctx,
+		tag.Upsert(metrics.ActorAddress, p.msg.Message.From.String()),
+		tag.Upsert(metrics.Method, p.method),
+		tag.Upsert(metrics.MsgResult, result),
+	)
+	stats.Record(ctx, metrics.MpoolMsgLifetime.M(end.Sub(p.seen).Seconds()))
+}
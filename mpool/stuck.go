@@ -0,0 +1,116 @@
+package mpool
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/methods"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	"github.com/ipfs/go-cid"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+// stuckRecord 检查地址在 mpool 中卡住的消息：
+// 最老消息的等待时间、nonce 空洞、GasFeeCap 低于当前 base fee 的消息，按方法统计
+func (m *Mpool) stuckRecord(ctx context.Context, addr address.Address, msgs []pendingMsg, head *types.TipSet) error {
+	api := m.dc.LotusApi
+
+	actor, err := api.StateGetActor(ctx, addr, head.Key())
+	if err != nil {
+		return err
+	}
+	next, err := api.MpoolGetNonce(ctx, addr)
+	if err != nil {
+		return err
+	}
+
+	//下一个块的 base fee 未知，用 head 的 ParentBaseFee 近似
+	baseFee := head.Blocks()[0].ParentBaseFee
+
+	var oldest time.Duration
+	nonces := map[uint64]struct{}{}
+	var maxNonce uint64
+	byMethod := map[string]int64{}
+	underpriced := map[string]int64{}
+
+	for _, p := range msgs {
+		msg := p.msg.Message
+		if age := time.Since(p.seen); age > oldest {
+			oldest = age
+		}
+
+		nonces[msg.Nonce] = struct{}{}
+		if msg.Nonce > maxNonce {
+			maxNonce = msg.Nonce
+		}
+
+		method := p.method
+		byMethod[method] += 1
+		if msg.GasFeeCap.LessThan(baseFee) {
+			underpriced[method] += 1
+		}
+	}
+
+	//actor nonce 到最大 pending nonce 之间缺失的 nonce 数量
+	var gap int64
+	for n := actor.Nonce; len(msgs) > 0 && n <= maxNonce; n++ {
+		if _, ok := nonces[n]; !ok {
+			gap++
+		}
+	}
+
+	log.Debugw("stuckRecord", "address", addr, "pending", len(msgs), "oldest", oldest, "actorNonce", actor.Nonce, "mpoolNonce", next, "gap", gap)
+	stats.Record(ctx,
+		metrics.MpoolOldestAge.M(oldest.Seconds()),
+		metrics.MpoolNonceGap.M(gap),
+		metrics.MpoolNonceAhead.M(int64(next)-int64(actor.Nonce)),
+	)
+
+	//上次有消息、这次没有的方法归零
+	for method := range m.methods[addr] {
+		if _, ok := byMethod[method]; !ok {
+			byMethod[method] = 0
+		}
+	}
+	m.methods[addr] = map[string]struct{}{}
+	for method, n := range byMethod {
+		if n > 0 {
+			m.methods[addr][method] = struct{}{}
+		}
+	}
+
+	for method := range byMethod {
+		ctx, _ := tag.New(ctx,
+			tag.Upsert(metrics.Method, method),
+		)
+		stats.Record(ctx,
+			metrics.MpoolMsgByMethod.M(byMethod[method]),
+			metrics.MpoolUnderpriced.M(underpriced[method]),
+		)
+	}
+
+	return nil
+}
+
+// methodName 接收方还不存在时只能返回 method number
+func (m *Mpool) methodName(ctx context.Context, to address.Address, num abi.MethodNum, tsk types.TipSetKey) string {
+	m.clk.Lock()
+	defer m.clk.Unlock()
+
+	code, ok := m.codes[to]
+	if !ok {
+		actor, err := m.dc.LotusApi.StateGetActor(ctx, to, tsk)
+		if err != nil {
+			log.Debugw("StateGetActor failed", "address", to, "err", err)
+			return methods.Name(cid.Undef, num)
+		}
+		code = actor.Code
+		m.codes[to] = code
+	}
+
+	return methods.Name(code, num)
+}
@@ -0,0 +1,105 @@
+// 事件驱动的采集：一个 ChainNotify 订阅，每隔 N 个高度触发一轮采集，
+// 同一轮内所有 collector 使用同一个 tipset。目前只有 fullnode 和 chain 注册为 collector，
+// 其他模块（storageminer、control、mpool、verifreg、deals、termination 等）仍按各自的频率在 head 上采集
+
+package notify
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/config"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+var log = logging.Logger("monitor/notify")
+
+// Collector 按 tipset 采集，所有 API 调用都要固定在 ts.Key()
+type Collector interface {
+	Name() string
+	Record(ctx context.Context, ts *types.TipSet) error
+}
+
+type Notify struct {
+	ctx context.Context
+	dc  *config.DynamicConfig
+
+	collectors []Collector
+	last       abi.ChainEpoch
+	running    atomic.Bool
+}
+
+func NewNotify(ctx context.Context, dc *config.DynamicConfig) *Notify {
+	return &Notify{
+		ctx: ctx,
+		dc:  dc,
+	}
+}
+
+func (n *Notify) Register(c Collector) {
+	n.collectors = append(n.collectors, c)
+}
+
+func (n *Notify) epochs() abi.ChainEpoch {
+	if n.dc.EventDriven.Epochs <= 0 {
+		return 1
+	}
+	return abi.ChainEpoch(n.dc.EventDriven.Epochs)
+}
+
+func (n *Notify) Run() {
+	Watch(n.ctx, n.dc.LotusApi, "notify", n.onChanges)
+}
+
+func (n *Notify) onChanges(changes []*api.HeadChange) {
+	head := Head(changes)
+	if head == nil || head.Height()-n.last < n.epochs() {
+		return
+	}
+
+	//上一轮还没结束时跳过，不堆积
+	if !n.running.CompareAndSwap(false, true) {
+		log.Warnw("previous round still running, skip", "height", head.Height())
+		metrics.RecordError(n.ctx, "notify/skipRound")
+		return
+	}
+	n.last = head.Height()
+	go func(ts *types.TipSet) {
+		defer n.running.Store(false)
+		n.round(ts)
+	}(head)
+}
+
+// round 并发执行所有 collector，完成后记录该 collector 采集时的高度
+func (n *Notify) round(ts *types.TipSet) {
+	stop := metrics.Timer(n.ctx, "notify/round")
+	defer stop()
+
+	log.Debugw("collection round", "height", ts.Height(), "tsk", ts.Key())
+
+	wg := sync.WaitGroup{}
+	for _, c := range n.collectors {
+		wg.Add(1)
+		go func(c Collector) {
+			defer wg.Done()
+
+			ctx, _ := tag.New(n.ctx,
+				tag.Upsert(metrics.RecordType, c.Name()),
+			)
+			if err := c.Record(ctx, ts); err != nil {
+				log.Errorw("collector record failed", "collector", c.Name(), "height", ts.Height(), "err", err)
+				metrics.RecordError(n.ctx, "notify/"+c.Name())
+				return
+			}
+			stats.Record(ctx, metrics.CollectHeight.M(int64(ts.Height())))
+		}(c)
+	}
+	wg.Wait()
+}
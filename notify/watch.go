@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+)
+
+// HeadChange 的类型，和 lotus chain/store 中的一致
+const (
+	HCRevert  = "revert"
+	HCApply   = "apply"
+	HCCurrent = "current"
+)
+
+// Watch 订阅 ChainNotify，每一批变化交给 handle 处理；
+// 订阅失败或 channel 关闭后 10s 重新订阅，直到 ctx 结束。name 用于日志和错误统计
+func Watch(ctx context.Context, fapi api.FullNode, name string, handle func(changes []*api.HeadChange)) {
+	go func() {
+		for {
+			if err := watch(ctx, fapi, handle); err != nil {
+				log.Errorw("chainNotify failed", "name", name, "err", err)
+				metrics.RecordError(ctx, name+"/chainNotify")
+			}
+
+			select {
+			case <-time.After(time.Second * 10):
+				log.Infow("resubscribe chain notify...", "name", name)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func watch(ctx context.Context, fapi api.FullNode, handle func(changes []*api.HeadChange)) error {
+	notifs, err := fapi.ChainNotify(ctx)
+	if err != nil {
+		return err
+	}
+
+	for changes := range notifs {
+		handle(changes)
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return errors.New("chain notify channel closed")
+}
+
+// Head 返回这一批变化之后的 head，只有 revert 时返回 nil
+func Head(changes []*api.HeadChange) *types.TipSet {
+	var head *types.TipSet
+	for _, change := range changes {
+		if change.Type == HCCurrent || change.Type == HCApply {
+			head = change.Val
+		}
+	}
+	return head
+}
+
+// Behind 返回落后 head lag 个高度的 tipset，按消息索引时用来避开链重组
+func Behind(ctx context.Context, fapi api.FullNode, head *types.TipSet, lag abi.ChainEpoch) (*types.TipSet, error) {
+	if lag <= 0 {
+		return head, nil
+	}
+	return fapi.ChainGetTipSetByHeight(ctx, head.Height()-lag, head.Key())
+}
+
+// ParentMessages 返回 ts 中执行的消息和对应的收据，ts 中包含的是父 tipset 中消息的执行结果
+func ParentMessages(ctx context.Context, fapi api.FullNode, ts *types.TipSet) ([]api.Message, []*types.MessageReceipt, error) {
+	bcid := ts.Blocks()[0].Cid()
+
+	msgs, err := fapi.ChainGetParentMessages(ctx, bcid)
+	if err != nil {
+		return nil, nil, err
+	}
+	rcpts, err := fapi.ChainGetParentReceipts(ctx, bcid)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(msgs) != len(rcpts) {
+		return nil, nil, fmt.Errorf("messages and receipts mismatch at %d: %d != %d", ts.Height(), len(msgs), len(rcpts))
+	}
+	return msgs, rcpts, nil
+}
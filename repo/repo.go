@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/ipfs/go-datastore"
+	levelds "github.com/ipfs/go-ds-leveldb"
+	logging "github.com/ipfs/go-log/v2"
+	ldbopts "github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+var log = logging.Logger("monitor/repo")
+
+const fsDatastore = "datastore"
+
+// OpenDatastore 打开 repo 目录下的本地 leveldb，不存在时自动创建
+func OpenDatastore(path string) (datastore.Batching, error) {
+	dir := filepath.Join(path, fsDatastore)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	ds, err := levelds.NewDatastore(dir, &levelds.Options{
+		Compression: ldbopts.NoCompression,
+		NoSync:      false,
+		Strict:      ldbopts.StrictAll,
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Infow("opened datastore", "path", dir)
+
+	return ds, nil
+}
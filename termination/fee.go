@@ -0,0 +1,105 @@
+package termination
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	miner13 "github.com/filecoin-project/go-state-types/builtin/v13/miner"
+	"github.com/filecoin-project/go-state-types/builtin/v13/util/smoothing"
+)
+
+const (
+	//罚金下限为 3.5 天的期望收益
+	lowerBoundProjection = abi.ChainEpoch(builtin.EpochsInDay * 35 / 10)
+	//sector 年龄最多按 140 天计算
+	lifetimeCap = abi.ChainEpoch(140 * builtin.EpochsInDay)
+	//按年龄累计的期望收益只罚一半
+	rewardFactorDenom = 2
+)
+
+// sector 计算罚金需要的扇区字段，v13 的 PowerBaseEpoch 在之前的版本中就是 Activation
+type sector struct {
+	number            abi.SectorNumber
+	expiration        abi.ChainEpoch
+	powerBase         abi.ChainEpoch
+	replacedAge       abi.ChainEpoch
+	qaPower           abi.StoragePower
+	pledge            abi.TokenAmount
+	dayReward         abi.TokenAmount
+	twentyDayReward   abi.TokenAmount
+	replacedDayReward abi.TokenAmount
+}
+
+// penalty 与 builtin-actors 的 pledge_penalty_for_termination 一致：
+// max(BR(3.5d), 激活时 20 天期望收益 + 激活时日收益 * min(年龄, 140d) / 2)
+func penalty(s *sector, h abi.ChainEpoch, reward, power smoothing.FilterEstimate) abi.TokenAmount {
+	age := h - s.powerBase
+	if age > lifetimeCap {
+		age = lifetimeCap
+	}
+	replacedAge := s.replacedAge
+	if replacedAge > lifetimeCap-age {
+		replacedAge = lifetimeCap - age
+	}
+
+	expected := big.Mul(s.dayReward, big.NewInt(int64(age)))
+	if !s.replacedDayReward.Nil() {
+		expected = big.Add(expected, big.Mul(s.replacedDayReward, big.NewInt(int64(replacedAge))))
+	}
+	penalized := big.Div(expected, big.NewInt(rewardFactorDenom*builtin.EpochsInDay))
+
+	lower := miner13.ExpectedRewardForPower(reward, power, s.qaPower, lowerBoundProjection)
+	return big.Max(lower, big.Add(s.twentyDayReward, penalized))
+}
+
+// 一次最多估算的 sector 数，防止 "0-18446744073709551615" 这样的范围
+const maxSectors = 1_000_000
+
+// ParseSectors 解析 "1,3,10-20" 形式的 sector 列表，返回去重排序后的 sector 号
+func ParseSectors(s string) ([]abi.SectorNumber, error) {
+	set := map[abi.SectorNumber]struct{}{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.ParseUint(strings.TrimSpace(from), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sector %q: %w", part, err)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.ParseUint(strings.TrimSpace(to), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid sector range %q: %w", part, err)
+			}
+			if end < start {
+				return nil, fmt.Errorf("invalid sector range %q", part)
+			}
+		}
+		if end > abi.MaxSectorNumber {
+			return nil, fmt.Errorf("sector number out of range %q", part)
+		}
+		if end-start >= maxSectors-uint64(len(set)) {
+			return nil, fmt.Errorf("too many sectors, at most %d", maxSectors)
+		}
+
+		for n := start; n <= end; n++ {
+			set[abi.SectorNumber(n)] = struct{}{}
+		}
+	}
+
+	ret := make([]abi.SectorNumber, 0, len(set))
+	for n := range set {
+		ret = append(ret, n)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i] < ret[j] })
+	return ret, nil
+}
@@ -0,0 +1,50 @@
+package termination
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/builtin/v13/util/smoothing"
+)
+
+func TestParseSectors(t *testing.T) {
+	got, err := ParseSectors("5, 1-3,2,10-10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []abi.SectorNumber{1, 2, 3, 5, 10}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for _, s := range []string{"a", "3-1", "1-x", "0-18446744073709551615", "18446744073709551615", "1-100000000", "0-999999,1000000-1000001"} {
+		if _, err := ParseSectors(s); err == nil {
+			t.Fatalf("expected error for %q", s)
+		}
+	}
+}
+
+func TestPenalty(t *testing.T) {
+	zero := smoothing.FilterEstimate{PositionEstimate: big.Zero(), VelocityEstimate: big.Zero()}
+	s := &sector{
+		qaPower:           big.Zero(),
+		dayReward:         big.NewInt(1000),
+		twentyDayReward:   big.NewInt(20000),
+		replacedDayReward: big.Zero(),
+	}
+
+	//10 天：20 天收益 + 日收益 * 10 / 2
+	fee := penalty(s, 10*builtin.EpochsInDay, zero, zero)
+	if !fee.Equals(big.NewInt(25000)) {
+		t.Fatalf("fee = %s, want 25000", fee)
+	}
+
+	//超过 140 天按 140 天计算
+	fee = penalty(s, 200*builtin.EpochsInDay, zero, zero)
+	if !fee.Equals(big.NewInt(90000)) {
+		t.Fatalf("fee = %s, want 90000", fee)
+	}
+}
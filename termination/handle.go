@@ -0,0 +1,50 @@
+package termination
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+// EstimateHandle GET /termination/estimate?miner=&sectors=1,3,10-20，sectors 为空时估算所有扇区
+func (t *Termination) EstimateHandle(w http.ResponseWriter, r *http.Request) {
+	log.Debugw("EstimateHandle", "path", r.URL.Path, "query", r.URL.RawQuery)
+
+	q := r.URL.Query()
+	maddr, err := address.NewFromString(q.Get("miner"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var sectors []abi.SectorNumber
+	if s := q.Get("sectors"); s != "" {
+		sectors, err = ParseSectors(s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	head, err := t.dc.LotusApi.ChainHead(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	est, err := t.Estimate(r.Context(), maddr, sectors, head)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(est)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(data)
+}
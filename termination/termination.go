@@ -0,0 +1,302 @@
+// 扇区终止罚金估算：根据扇区链上信息和当前 reward/power 状态，计算终止罚金、
+// 可取回的质押和剩余生命周期内损失的期望收益，并定期统计每个 miner 终止所有扇区的罚金
+
+package termination
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/big"
+	miner13 "github.com/filecoin-project/go-state-types/builtin/v13/miner"
+	adt13 "github.com/filecoin-project/go-state-types/builtin/v13/util/adt"
+	"github.com/filecoin-project/go-state-types/builtin/v13/util/smoothing"
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/actors/adt"
+	"github.com/filecoin-project/lotus/chain/actors/builtin"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/miner"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/power"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/reward"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/config"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+var log = logging.Logger("monitor/termination")
+
+type SectorEstimate struct {
+	Sector         abi.SectorNumber `json:"sector"`
+	Expiration     abi.ChainEpoch   `json:"expiration"`
+	TerminationFee abi.TokenAmount  `json:"terminationFee"`
+	Pledge         abi.TokenAmount  `json:"pledge"`      //扇区的初始质押，终止后解锁，未扣除罚金
+	LostRewards    abi.TokenAmount  `json:"lostRewards"` //剩余生命周期的期望收益
+}
+
+// Estimate 一组扇区的终止估算，NotFound 为不存在或已终止的扇区
+type Estimate struct {
+	Miner          string             `json:"miner"`
+	Height         abi.ChainEpoch     `json:"height"`
+	Sectors        []SectorEstimate   `json:"sectors"`
+	NotFound       []abi.SectorNumber `json:"notFound"`
+	TerminationFee abi.TokenAmount    `json:"terminationFee"`
+	Pledge         abi.TokenAmount    `json:"pledge"`
+	LostRewards    abi.TokenAmount    `json:"lostRewards"`
+}
+
+type Termination struct {
+	ctx     context.Context
+	dc      *config.DynamicConfig
+	store   adt.Store
+	store13 adt13.Store
+}
+
+func NewTermination(ctx context.Context, dc *config.DynamicConfig) *Termination {
+	cst := cbor.NewCborStore(blockstore.NewAPIBlockstore(dc.LotusApi))
+	return &Termination{
+		ctx:     ctx,
+		dc:      dc,
+		store:   adt.WrapStore(ctx, cst),
+		store13: adt13.WrapStore(ctx, cst),
+	}
+}
+
+// Run 统计 exposure 需要读取所有扇区，recordInterval.termination 为 0 时不统计
+func (t *Termination) Run() {
+	interval := time.Duration(t.dc.RecordInterval.Termination)
+	if interval == 0 {
+		log.Info("termination exposure disabled")
+		return
+	}
+
+	go func() {
+		t.record()
+		tk := time.NewTicker(interval)
+		for {
+			select {
+			case <-tk.C:
+				t.record()
+			case <-t.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// record 每个 miner 终止所有扇区需要的罚金
+func (t *Termination) record() {
+	stop := metrics.Timer(t.ctx, "termination/record")
+	defer stop()
+
+	head, err := t.dc.LotusApi.ChainHead(t.ctx)
+	if err != nil {
+		log.Errorw("ChainHead failed", "err", err)
+		metrics.RecordError(t.ctx, "termination/record")
+		return
+	}
+
+	for _, maddr := range t.dc.MinersList() {
+		est, err := t.Estimate(t.ctx, maddr, nil, head)
+		if err != nil {
+			log.Errorw("termination estimate failed", "miner", maddr, "err", err)
+			metrics.RecordError(t.ctx, "termination/record")
+			continue
+		}
+
+		ctx, _ := tag.New(t.ctx,
+			tag.Upsert(metrics.MinerID, maddr.String()),
+		)
+		log.Debugw("termination record", "miner", maddr, "sectors", len(est.Sectors), "fee", types.FIL(est.TerminationFee).Short())
+		stats.Record(ctx, metrics.TerminationExposure.M(types.BigDivFloat(est.TerminationFee, types.FromFil(1))))
+	}
+}
+
+// Estimate 估算在 ts 高度终止 sectors 的罚金，sectors 为 nil 时估算所有扇区
+func (t *Termination) Estimate(ctx context.Context, maddr address.Address, sectors []abi.SectorNumber, ts *types.TipSet) (*Estimate, error) {
+	api := t.dc.LotusApi
+	h := ts.Height()
+
+	ract, err := api.StateGetActor(ctx, reward.Address, ts.Key())
+	if err != nil {
+		return nil, err
+	}
+	rs, err := reward.Load(t.store, ract)
+	if err != nil {
+		return nil, err
+	}
+	rewardSmoothed, err := rs.ThisEpochRewardSmoothed()
+	if err != nil {
+		return nil, err
+	}
+
+	pact, err := api.StateGetActor(ctx, power.Address, ts.Key())
+	if err != nil {
+		return nil, err
+	}
+	ps, err := power.Load(t.store, pact)
+	if err != nil {
+		return nil, err
+	}
+	powerSmoothed, err := ps.TotalPowerSmoothed()
+	if err != nil {
+		return nil, err
+	}
+
+	mi, err := t.dc.Cache.MinerInfo(ctx, maddr, ts.Key())
+	if err != nil {
+		return nil, err
+	}
+	infos, notFound, err := t.loadSectors(ctx, maddr, mi.SectorSize, sectors, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	est := &Estimate{
+		Miner:          maddr.String(),
+		Height:         h,
+		Sectors:        make([]SectorEstimate, 0, len(infos)),
+		NotFound:       notFound,
+		TerminationFee: big.Zero(),
+		Pledge:         big.Zero(),
+		LostRewards:    big.Zero(),
+	}
+	re, pe := toFilter(rewardSmoothed), toFilter(powerSmoothed)
+	for _, s := range infos {
+		fee := penalty(s, h, re, pe)
+		lost := big.Zero()
+		if s.expiration > h {
+			lost = miner13.ExpectedRewardForPower(re, pe, s.qaPower, s.expiration-h)
+		}
+
+		est.Sectors = append(est.Sectors, SectorEstimate{
+			Sector:         s.number,
+			Expiration:     s.expiration,
+			TerminationFee: fee,
+			Pledge:         s.pledge,
+			LostRewards:    lost,
+		})
+		est.TerminationFee = big.Add(est.TerminationFee, fee)
+		est.Pledge = big.Add(est.Pledge, s.pledge)
+		est.LostRewards = big.Add(est.LostRewards, lost)
+	}
+
+	return est, nil
+}
+
+// loadSectors v13 的 PowerBaseEpoch 和 ReplacedDayReward 不在 lotus v1.26.2 的 SectorOnChainInfo（v9 结构）中，
+// 直接读取 miner 状态；之前版本的 actor 使用 lotus 的 miner.State
+func (t *Termination) loadSectors(ctx context.Context, maddr address.Address, size abi.SectorSize, sectors []abi.SectorNumber, ts *types.TipSet) ([]*sector, []abi.SectorNumber, error) {
+	act, err := t.dc.LotusApi.StateGetActor(ctx, maddr, ts.Key())
+	if err != nil {
+		return nil, nil, err
+	}
+	mst, err := miner.Load(t.store, act)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if mst.ActorVersion() != actorstypes.Version13 {
+		return loadLotusSectors(mst, size, sectors)
+	}
+
+	var st miner13.State
+	if err := t.store13.Get(ctx, act.Head, &st); err != nil {
+		return nil, nil, err
+	}
+	arr, err := adt13.AsArray(t.store13, st.Sectors, miner13.SectorsAmtBitwidth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var infos []*sector
+	var notFound []abi.SectorNumber
+	if sectors == nil {
+		var info miner13.SectorOnChainInfo
+		err := arr.ForEach(&info, func(i int64) error {
+			infos = append(infos, fromV13(size, &info))
+			return nil
+		})
+		return infos, notFound, err
+	}
+
+	for _, n := range sectors {
+		var info miner13.SectorOnChainInfo
+		found, err := arr.Get(uint64(n), &info)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !found {
+			notFound = append(notFound, n)
+			continue
+		}
+		infos = append(infos, fromV13(size, &info))
+	}
+	return infos, notFound, nil
+}
+
+func loadLotusSectors(mst miner.State, size abi.SectorSize, sectors []abi.SectorNumber) ([]*sector, []abi.SectorNumber, error) {
+	var list []*miner.SectorOnChainInfo
+	var notFound []abi.SectorNumber
+	if sectors == nil {
+		var err error
+		list, err = mst.LoadSectors(nil)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		for _, n := range sectors {
+			s, err := mst.GetSector(n)
+			if err != nil {
+				return nil, nil, err
+			}
+			if s == nil {
+				notFound = append(notFound, n)
+				continue
+			}
+			list = append(list, s)
+		}
+	}
+
+	infos := make([]*sector, 0, len(list))
+	for _, s := range list {
+		infos = append(infos, &sector{
+			number:            s.SectorNumber,
+			expiration:        s.Expiration,
+			powerBase:         s.Activation,
+			replacedAge:       s.ReplacedSectorAge,
+			qaPower:           miner13.QAPowerForWeight(size, s.Expiration-s.Activation, s.DealWeight, s.VerifiedDealWeight),
+			pledge:            s.InitialPledge,
+			dayReward:         s.ExpectedDayReward,
+			twentyDayReward:   s.ExpectedStoragePledge,
+			replacedDayReward: s.ReplacedDayReward,
+		})
+	}
+	return infos, notFound, nil
+}
+
+func fromV13(size abi.SectorSize, s *miner13.SectorOnChainInfo) *sector {
+	return &sector{
+		number:            s.SectorNumber,
+		expiration:        s.Expiration,
+		powerBase:         s.PowerBaseEpoch,
+		replacedAge:       s.PowerBaseEpoch - s.Activation,
+		qaPower:           miner13.QAPowerForSector(size, s),
+		pledge:            s.InitialPledge,
+		dayReward:         s.ExpectedDayReward,
+		twentyDayReward:   s.ExpectedStoragePledge,
+		replacedDayReward: s.ReplacedDayReward,
+	}
+}
+
+func toFilter(f builtin.FilterEstimate) smoothing.FilterEstimate {
+	return smoothing.FilterEstimate{
+		PositionEstimate: f.PositionEstimate,
+		VelocityEstimate: f.VelocityEstimate,
+	}
+}
@@ -0,0 +1,224 @@
+// FIL+ 数据的 claim 和 allocation：有效 claim 的数量和大小、N 天内按 TermMax 到期的 claim、
+// 需要在 Expiration 之前封装的待处理 allocation
+
+package verifreg
+
+import (
+	"context"
+	"time"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/gh-efforts/lotus-monitor/config"
+	"github.com/gh-efforts/lotus-monitor/metrics"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+)
+
+var log = logging.Logger("monitor/verifreg")
+
+const (
+	defaultInterval     = time.Minute * 10
+	defaultExpiringDays = 30
+)
+
+type summary struct {
+	count int64
+	size  int64
+}
+
+func (s *summary) add(size abi.PaddedPieceSize) {
+	s.count++
+	s.size += int64(size)
+}
+
+type minerStat struct {
+	claims      summary
+	expiring    summary //N 天内到期的 claim
+	allocations summary //未过期的待处理 allocation
+	urgent      summary //N 天内过期的待处理 allocation
+	expired     summary //已过期仍未封装的 allocation
+	next        abi.ChainEpoch
+}
+
+type Verifreg struct {
+	ctx context.Context
+	dc  *config.DynamicConfig
+}
+
+func NewVerifreg(ctx context.Context, dc *config.DynamicConfig) *Verifreg {
+	return &Verifreg{
+		ctx: ctx,
+		dc:  dc,
+	}
+}
+
+// Run 数据变化慢且每个 client 都要查询一次 allocation，事件驱动模式下也按 recordInterval.verifreg 采集
+func (v *Verifreg) Run() {
+	interval := time.Duration(v.dc.RecordInterval.Verifreg)
+	if interval == 0 {
+		interval = defaultInterval
+	}
+
+	go func() {
+		v.record()
+		t := time.NewTicker(interval)
+		for {
+			select {
+			case <-t.C:
+				v.record()
+			case <-v.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (v *Verifreg) record() {
+	stop := metrics.Timer(v.ctx, "verifreg/record")
+	defer stop()
+
+	head, err := v.dc.LotusApi.ChainHead(v.ctx)
+	if err != nil {
+		log.Errorw("ChainHead failed", "err", err)
+		metrics.RecordError(v.ctx, "verifreg/record")
+		return
+	}
+
+	if err := v.Record(v.ctx, head); err != nil {
+		log.Errorw("verifreg record failed", "height", head.Height(), "err", err)
+		metrics.RecordError(v.ctx, "verifreg/record")
+	}
+}
+
+func (v *Verifreg) Name() string {
+	return "verifreg"
+}
+
+// Record 记录 ts 高度所有监控 miner 的 claim 和 allocation
+func (v *Verifreg) Record(ctx context.Context, ts *types.TipSet) error {
+	api := v.dc.LotusApi
+	h := ts.Height()
+	days := v.dc.VerifregExpiringDays
+	if days <= 0 {
+		days = defaultExpiringDays
+	}
+	window := h + abi.ChainEpoch(days*builtin.EpochsInDay)
+
+	providers := map[abi.ActorID]address.Address{}
+	stat := map[address.Address]*minerStat{}
+	for _, maddr := range v.dc.MinersList() {
+		idAddr, err := v.dc.Cache.LookupID(ctx, maddr)
+		if err != nil {
+			log.Errorw("LookupID failed", "miner", maddr, "err", err)
+			metrics.RecordError(ctx, "verifreg/record")
+			continue
+		}
+		id, err := address.IDFromAddress(idAddr)
+		if err != nil {
+			return err
+		}
+		providers[abi.ActorID(id)] = maddr
+		stat[maddr] = &minerStat{}
+	}
+
+	//allocation 只能按 client 查询，client 来自监控 miner 已有的 claim 和 verifregClients 配置
+	clients := map[address.Address]struct{}{}
+	for _, c := range v.dc.VerifregClients {
+		caddr, err := address.NewFromString(c)
+		if err != nil {
+			log.Errorw("invalid verifregClients entry", "client", c, "err", err)
+			metrics.RecordError(ctx, "verifreg/clients")
+			continue
+		}
+		clients[caddr] = struct{}{}
+	}
+
+	for _, maddr := range providers {
+		claims, err := api.StateGetClaims(ctx, maddr, ts.Key())
+		if err != nil {
+			log.Errorw("StateGetClaims failed", "miner", maddr, "err", err)
+			metrics.RecordError(ctx, "verifreg/claims")
+			delete(stat, maddr)
+			continue
+		}
+
+		s := stat[maddr]
+		for _, c := range claims {
+			if caddr, err := address.NewIDAddress(uint64(c.Client)); err == nil {
+				clients[caddr] = struct{}{}
+			}
+
+			end := c.TermStart + c.TermMax
+			//到期后 claim 仍留在状态中，直到被 RemoveExpiredClaims 清理
+			if c.TermStart > h || end <= h {
+				continue
+			}
+			s.claims.add(c.Size)
+			if end <= window {
+				s.expiring.add(c.Size)
+			}
+		}
+	}
+
+	for caddr := range clients {
+		allocs, err := api.StateGetAllocations(ctx, caddr, ts.Key())
+		if err != nil {
+			log.Errorw("StateGetAllocations failed", "client", caddr, "err", err)
+			metrics.RecordError(ctx, "verifreg/allocations")
+			continue
+		}
+		for _, a := range allocs {
+			maddr, ok := providers[a.Provider]
+			if !ok {
+				continue
+			}
+			s, ok := stat[maddr]
+			if !ok {
+				continue
+			}
+
+			if a.Expiration < h {
+				s.expired.add(a.Size)
+				continue
+			}
+			s.allocations.add(a.Size)
+			if a.Expiration <= window {
+				s.urgent.add(a.Size)
+			}
+			if s.next == 0 || a.Expiration < s.next {
+				s.next = a.Expiration
+			}
+		}
+	}
+
+	for maddr, s := range stat {
+		ctx, _ := tag.New(ctx,
+			tag.Upsert(metrics.MinerID, maddr.String()),
+		)
+		var remaining int64
+		if s.next > 0 {
+			remaining = int64(s.next - h)
+		}
+
+		log.Debugw("verifreg record", "miner", maddr, "claims", s.claims.count, "expiring", s.expiring.count, "allocations", s.allocations.count, "expired", s.expired.count)
+		stats.Record(ctx,
+			metrics.VerifregClaims.M(s.claims.count),
+			metrics.VerifregClaimsSize.M(s.claims.size),
+			metrics.VerifregClaimsExpiring.M(s.expiring.count),
+			metrics.VerifregClaimsExpiringSize.M(s.expiring.size),
+			metrics.VerifregAllocations.M(s.allocations.count),
+			metrics.VerifregAllocationsSize.M(s.allocations.size),
+			metrics.VerifregAllocationsExpiring.M(s.urgent.count),
+			metrics.VerifregAllocationsExpiringSize.M(s.urgent.size),
+			metrics.VerifregAllocationsExpired.M(s.expired.count),
+			metrics.VerifregAllocationsExpiredSize.M(s.expired.size),
+			metrics.VerifregAllocationNextExpiration.M(remaining),
+		)
+	}
+
+	return nil
+}